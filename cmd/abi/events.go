@@ -0,0 +1,64 @@
+package abi
+
+import (
+	"errors"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var EventsCmd = &cobra.Command{
+	Use:   "events [file|address]",
+	Short: "List the events of an ABI with their signatures and topic hashes",
+	Long: `List every event of an ABI with its canonical signature, topic0 hash and its
+indexed and data arguments.
+
+Arguments:
+  file    - JSON ABI file, - reads the ABI from stdin
+  address - The address of a stored ABI on --chain
+
+Example:
+  heimdahl abi events weth.abi.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := loadABI(args[0])
+		if err != nil {
+			return err
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		for _, e := range a.Events() {
+			if err := r.Write(decode.EventRecord(e)); err != nil {
+				return err
+			}
+		}
+		return r.Close()
+	},
+}
+
+// loadABI reads an ABI from a file, stdin or the ABI store
+func loadABI(source string) (*decode.ABI, error) {
+	if source == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return decode.ParseABI(b)
+	}
+
+	a, err := decode.LoadABI(source)
+	if !errors.Is(err, os.ErrNotExist) {
+		return a, err
+	}
+	stored, serr := config.LoadABI(chain, source)
+	if serr != nil {
+		// neither a file nor a stored address, report the missing file
+		return nil, err
+	}
+	return decode.ParseABI(stored.ABI)
+}
@@ -0,0 +1,64 @@
+package abi
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var name string
+
+var ImportCmd = &cobra.Command{
+	Use:   "import [address] [file]",
+	Short: "Store the ABI of a contract",
+	Long: `Store the ABI of a contract in the local ABI store, replacing any ABI stored
+for the same chain and address.
+
+Arguments:
+  address - The contract address
+  file    - JSON ABI or Foundry/Hardhat artifact file, - reads it from stdin
+
+Example:
+  heimdahl abi import 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2 weth.abi.json --name WETH`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, path := args[0], args[1]
+
+		var b []byte
+		var err error
+		if path == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading ABI file: %w", err)
+		}
+
+		raw, err := artifact.ABI(b)
+		if err != nil {
+			return fmt.Errorf("error parsing ABI: %w", err)
+		}
+		parsed, err := decode.ParseABI(raw)
+		if err != nil {
+			return err
+		}
+
+		stored := &config.StoredABI{Chain: chain, Address: address, Name: name, ABI: raw}
+		if err := config.SaveABI(stored); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported ABI for %s %s (%d events, %d functions)\n",
+			stored.Chain, stored.Address, len(parsed.ABI.Events), len(parsed.ABI.Methods))
+		return nil
+	},
+}
+
+func init() {
+	ImportCmd.Flags().StringVar(&name, "name", "", "Name of the contract")
+}
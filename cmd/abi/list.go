@@ -0,0 +1,44 @@
+package abi
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored ABIs",
+	Long:  `List stored ABIs, only those of --chain when it is set.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := ""
+		if cmd.Flags().Changed("chain") {
+			filter = chain
+		}
+		abis, err := config.ListABIs(filter)
+		if err != nil {
+			return err
+		}
+		if len(abis) == 0 {
+			fmt.Fprintln(os.Stderr, `No ABIs stored, add one with "heimdahl abi import"`)
+			return nil
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		for _, a := range abis {
+			rec, err := record(a)
+			if err != nil {
+				return fmt.Errorf("ABI for %s %s: %w", a.Chain, a.Address, err)
+			}
+			if err := r.Write(rec); err != nil {
+				return err
+			}
+		}
+		return r.Close()
+	},
+}
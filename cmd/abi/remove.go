@@ -0,0 +1,20 @@
+package abi
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var RemoveCmd = &cobra.Command{
+	Use:   "remove [address]",
+	Short: "Remove a stored ABI",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.RemoveABI(chain, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed ABI for %s %s\n", chain, args[0])
+		return nil
+	},
+}
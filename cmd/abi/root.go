@@ -0,0 +1,56 @@
+package abi
+
+import (
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+	"sort"
+)
+
+var AbiCmd = &cobra.Command{
+	Use:   "abi",
+	Short: "Manage the local ABI store",
+	Long: `Manage contract ABIs kept on this machine, keyed by chain and address.
+
+Stored ABIs are used by "heimdahl decode log" to decode raw logs offline.`,
+}
+
+var chain string
+
+func init() {
+	AbiCmd.AddCommand(ImportCmd)
+	AbiCmd.AddCommand(ListCmd)
+	AbiCmd.AddCommand(ShowCmd)
+	AbiCmd.AddCommand(RemoveCmd)
+	AbiCmd.AddCommand(EventsCmd)
+
+	AbiCmd.PersistentFlags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum)")
+}
+
+// record describes a stored ABI with the names of its events and functions
+func record(a *config.StoredABI) (format.Record, error) {
+	parsed, err := decode.ParseABI(a.ABI)
+	if err != nil {
+		return nil, err
+	}
+
+	functions := make([]string, 0, len(parsed.ABI.Methods))
+	seen := map[string]bool{}
+	for _, m := range parsed.ABI.Methods {
+		if !seen[m.RawName] {
+			seen[m.RawName] = true
+			functions = append(functions, m.RawName)
+		}
+	}
+	sort.Strings(functions)
+
+	return format.Record{
+		{Name: "chain", Value: a.Chain},
+		{Name: "address", Value: a.Address},
+		{Name: "name", Value: a.Name},
+		{Name: "events", Value: parsed.EventNames()},
+		{Name: "functions", Value: functions},
+		{Name: "imported", Value: a.Imported.Local()},
+	}, nil
+}
@@ -0,0 +1,53 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var ShowCmd = &cobra.Command{
+	Use:   "show [address]",
+	Short: "Show a stored ABI",
+	Long: `Show the events and functions of a stored ABI, or the ABI itself with --raw.
+
+Arguments:
+  address - The contract address`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stored, err := config.LoadABI(chain, args[0])
+		if err != nil {
+			return err
+		}
+
+		if raw, _ := cmd.Flags().GetBool("raw"); raw {
+			var out bytes.Buffer
+			if err := json.Indent(&out, stored.ABI, "", "  "); err != nil {
+				return err
+			}
+			out.WriteByte('\n')
+			_, err := out.WriteTo(os.Stdout)
+			return err
+		}
+
+		rec, err := record(stored)
+		if err != nil {
+			return fmt.Errorf("ABI for %s %s: %w", stored.Chain, stored.Address, err)
+		}
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		if err := r.Write(rec); err != nil {
+			return err
+		}
+		return r.Close()
+	},
+}
+
+func init() {
+	ShowCmd.Flags().Bool("raw", false, "Print the ABI JSON")
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/manifoldco/promptui"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fromStdin     bool
+	usePassphrase bool
+	useMachineKey bool
+)
+
+var LoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store an API key for the active profile",
+	Long: `Store an API key for the active profile in the encrypted credentials store.
+
+The key is prompted for, or read from standard input with --stdin, so it never
+appears in process arguments or shell history:
+
+  heimdahl auth login
+  heimdahl --profile staging auth login --stdin < staging.key
+
+Keys are encrypted with a key derived from the machine unless --passphrase is
+given. A store keeps its encryption on later logins until --passphrase or
+--machine-key switches it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := readKey()
+		if err != nil {
+			return err
+		}
+
+		creds, err := config.LoadCredentials()
+		if err != nil {
+			return err
+		}
+
+		// keep the store's encryption unless asked to change it, so a
+		// passphrase protected store is never silently downgraded
+		switch {
+		case usePassphrase:
+			err = creds.SetMode(config.ModePassphrase)
+		case useMachineKey:
+			err = creds.SetMode(config.ModeMachine)
+		}
+		if err != nil {
+			return err
+		}
+
+		creds.Keys[config.ProfileName()] = key
+		if err := creds.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Stored API key for profile %s (%s encryption)\n", config.ProfileName(), creds.Mode)
+		return nil
+	},
+}
+
+func readKey() (string, error) {
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read API key from stdin: %w", err)
+		}
+		key := strings.TrimSpace(line)
+		if key == "" {
+			return "", errors.New("API key must not be empty")
+		}
+		return key, nil
+	}
+
+	prompt := promptui.Prompt{
+		Label: "API key",
+		Mask:  '*',
+		Validate: func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return errors.New("API key must not be empty")
+			}
+			return nil
+		},
+	}
+	key, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("read API key: %w", err)
+	}
+	return strings.TrimSpace(key), nil
+}
+
+func init() {
+	LoginCmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the API key from standard input")
+	LoginCmd.Flags().BoolVar(&usePassphrase, "passphrase", false, "Encrypt stored keys with a passphrase instead of the machine key")
+	LoginCmd.Flags().BoolVar(&useMachineKey, "machine-key", false, "Encrypt stored keys with the machine key instead of a passphrase")
+	LoginCmd.MarkFlagsMutuallyExclusive("passphrase", "machine-key")
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var all bool
+
+var LogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored API key of the active profile",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := config.LoadCredentials()
+		if err != nil {
+			return err
+		}
+
+		if all {
+			creds.Keys = map[string]string{}
+		} else {
+			if _, ok := creds.Keys[config.ProfileName()]; !ok {
+				fmt.Printf("No API key stored for profile %s\n", config.ProfileName())
+				return nil
+			}
+			delete(creds.Keys, config.ProfileName())
+		}
+
+		if err := creds.Save(); err != nil {
+			return err
+		}
+
+		if all {
+			fmt.Println("Removed all stored API keys")
+		} else {
+			fmt.Printf("Removed API key for profile %s\n", config.ProfileName())
+		}
+		return nil
+	},
+}
+
+func init() {
+	LogoutCmd.Flags().BoolVar(&all, "all", false, "Remove stored keys of all profiles")
+}
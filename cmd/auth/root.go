@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var AuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage stored API keys",
+	Long: `Manage API keys stored encrypted in ~/.config/heimdahl/credentials.enc.
+
+Keys are stored per profile. By default they are encrypted with a key derived
+from machine and user identifiers; use "auth login --passphrase" to protect them
+with a passphrase instead (read from HEIMDAHL_PASSPHRASE or prompted).`,
+}
+
+func init() {
+	AuthCmd.AddCommand(LoginCmd)
+	AuthCmd.AddCommand(LogoutCmd)
+	AuthCmd.AddCommand(StatusCmd)
+}
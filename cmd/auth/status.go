@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which API key is used and where it comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, source, err := config.ResolveApiKey()
+		if err != nil {
+			return err
+		}
+
+		path, _ := config.CredentialsPath()
+		fmt.Printf("Profile:     %s\n", config.ProfileName())
+		fmt.Printf("API key:     %s\n", config.MaskKey(key))
+		fmt.Printf("Source:      %s\n", source)
+		fmt.Printf("Credentials: %s\n", path)
+		if source == config.KeySourceDemo {
+			fmt.Println("\nUsing the public demo key, run \"heimdahl auth login\" to store your own.")
+		}
+		return nil
+	},
+}
@@ -1,21 +1,12 @@
 package chain
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
-	"io"
-	"net/http"
 
 	"github.com/spf13/cobra"
 )
 
-type ChainInfo struct {
-	Chain   string `json:"chain_name"`
-	Network string `json:"chain_network"`
-	ChainID int    `json:"chain_id"`
-}
-
 var (
 	chain   string
 	network string
@@ -24,45 +15,24 @@ var (
 var ShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show chain information",
-	Run: func(cmd *cobra.Command, args []string) {
-		// change the endpoint
-		url := fmt.Sprintf("%s/v1/chains/%s/%s", config.GetHost(), chain, network) // Use the global host variable
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.NewClient()
 		if err != nil {
-			fmt.Println("Error making GET request:", err)
-			return
+			return err
 		}
-
-		req.Header.Set("Authorization", "Bearer "+config.GetApiKey())
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
+		chainInfo, err := c.GetChain(cmd.Context(), chain, network)
 		if err != nil {
-			fmt.Println("Error performing request:", err)
-			return
-		}
-
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("Failed to get event listener: %s\n", resp.Status)
-			return
+			return fmt.Errorf("failed to get chain %s/%s: %w", chain, network, err)
 		}
 
-		body, err := io.ReadAll(resp.Body)
+		r, err := config.NewRenderer(false)
 		if err != nil {
-			fmt.Println("Error reading response body:", err)
-			return
+			return err
 		}
-
-		var contractInfo ChainInfo
-		err = json.Unmarshal(body, &contractInfo)
-		if err != nil {
-			fmt.Println("Error unmarshalling JSON:", err)
-			return
+		if err := r.Write(chainInfo.Record()); err != nil {
+			return err
 		}
-
-		fmt.Printf("Chain: %s\nNetwork: %s\nChain ID:%d\n ", contractInfo.Chain, contractInfo.Network, contractInfo.ChainID)
+		return r.Close()
 	},
 }
 
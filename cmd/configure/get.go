@@ -0,0 +1,49 @@
+package configure
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var GetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show settings of the active profile",
+	Long: `Show a single setting, or all settings when key is omitted, of the active profile.
+
+Keys: host, secure, chain, network, output
+
+The API key is not a setting, store it encrypted with "heimdahl auth login".`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := config.LoadFile()
+		if err != nil {
+			return err
+		}
+
+		name := f.ProfileName()
+		p, ok := f.Profile(name, false)
+		if !ok {
+			return fmt.Errorf("profile %q not found", name)
+		}
+
+		keys := config.ProfileKeys
+		if len(args) == 1 {
+			keys = args[:1]
+		}
+
+		for _, key := range keys {
+			value, err := p.Get(key)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				fmt.Println(value)
+				continue
+			}
+			fmt.Printf("%-8s %s\n", key, value)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,38 @@
+package configure
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := config.LoadFile()
+		if err != nil {
+			return err
+		}
+
+		names := f.ProfileNames()
+		if len(names) == 0 {
+			path, _ := config.FilePath()
+			fmt.Printf("No profiles configured in %s\n", path)
+			return nil
+		}
+
+		active := f.ProfileName()
+		fmt.Printf("%-2s %-15s %-30s %-10s %-10s\n", "", "PROFILE", "HOST", "CHAIN", "NETWORK")
+		for _, name := range names {
+			p := f.Profiles[name]
+			marker := ""
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%-2s %-15s %-30s %-10s %-10s\n", marker, name, p.Host, p.Chain, p.Network)
+		}
+		return nil
+	},
+}
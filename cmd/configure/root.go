@@ -0,0 +1,23 @@
+package configure
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration profiles",
+	Long: `Manage configuration profiles stored in ~/.config/heimdahl/config.yaml.
+
+Each profile holds host, secure, chain, network and output settings. Its API
+key is stored encrypted with "heimdahl auth login".
+The active profile is selected with --profile, the HEIMDAHL_PROFILE environment
+variable or "heimdahl config use", in that order.`,
+}
+
+func init() {
+	ConfigCmd.AddCommand(GetCmd)
+	ConfigCmd.AddCommand(SetCmd)
+	ConfigCmd.AddCommand(UseCmd)
+	ConfigCmd.AddCommand(ListCmd)
+}
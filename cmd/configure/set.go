@@ -0,0 +1,41 @@
+package configure
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var SetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Change a setting of the active profile",
+	Long: `Change a setting of the active profile, creating the profile if needed.
+An empty value clears the setting.
+
+Keys: host, secure, chain, network, output
+
+The API key is not a setting, store it encrypted with "heimdahl auth login".
+
+Example:
+  heimdahl --profile staging config set host staging.api.heimdahl.xyz`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := config.LoadFile()
+		if err != nil {
+			return err
+		}
+
+		name := f.ProfileName()
+		p, _ := f.Profile(name, true)
+		if err := p.Set(args[0], args[1]); err != nil {
+			return err
+		}
+
+		if err := f.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s for profile %s\n", args[0], name)
+		return nil
+	},
+}
@@ -0,0 +1,38 @@
+package configure
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+var create bool
+
+var UseCmd = &cobra.Command{
+	Use:   "use [profile]",
+	Short: "Select the profile used by default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := config.LoadFile()
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		if _, ok := f.Profile(name, create); !ok {
+			return fmt.Errorf("profile %q not found, use --create to add it", name)
+		}
+
+		f.CurrentProfile = name
+		if err := f.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Using profile %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	UseCmd.Flags().BoolVar(&create, "create", false, "Create the profile if it does not exist")
+}
@@ -1,26 +1,16 @@
 package contract
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
+	"strings"
 )
 
-type ContractParams struct {
-	ProjectName     string  `json:"project_name"`
-	Chain           string  `json:"chain"`
-	Network         string  `json:"network"`
-	ContractAddress string  `json:"contract_address"`
-	ContractName    string  `json:"contract_name"`
-	EventNames      *string `json:"event_names"`
-	RawABI          *string `json:"raw_abi,omitempty"`
-}
-
 var (
 	eventNames string
 	rawABI     string
@@ -34,13 +24,16 @@ var AddCmd = &cobra.Command{
 
 Arguments:
   address - The contract address (required)
-  name   - A user-defined name for the contract (required)`,
+  name   - A user-defined name for the contract (required)
+
+Use "heimdahl contract events --abi_file <file>" to see the events of an ABI
+before adding the contract.`,
 	Args: cobra.ExactArgs(2), // Expect exactly 2 arguments
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		contractAddress := args[0]
 		contractName := args[1]
 
-		params := ContractParams{
+		params := lib.ContractParams{
 			Chain:           chain,
 			Network:         network,
 			ContractAddress: contractAddress,
@@ -50,67 +43,89 @@ Arguments:
 		if rawABIFile != "" {
 			abb, err := os.ReadFile(rawABIFile)
 			if err != nil {
-				fmt.Println("Error reading ABI file:", err)
-				return
-			}
-
-			_, err = abi.JSON(bytes.NewReader(abb))
-			if err != nil {
-				fmt.Println("Error parsing ABI:", err)
-				return
+				return fmt.Errorf("error reading ABI file: %w", err)
 			}
 			rawABI = string(abb)
 		}
 
+		var contractABI *decode.ABI
 		if rawABI != "" {
+			// send the bare ABI when given a build artifact
+			raw, err := artifact.ABI([]byte(rawABI))
+			if err != nil {
+				return fmt.Errorf("error parsing ABI: %w", err)
+			}
+			rawABI = string(raw)
+
+			parsed, err := decode.ParseABI(raw)
+			if err != nil {
+				return err
+			}
+			contractABI = parsed
 			params.RawABI = &rawABI
 		}
 
 		if eventNames != "" {
+			// without an ABI given, check against the one stored for the
+			// contract, if any
+			if contractABI == nil {
+				if stored, err := storedABI(contractAddress); err == nil {
+					contractABI = stored
+				}
+			}
+			if contractABI != nil {
+				if err := checkEventNames(contractABI, eventNames); err != nil {
+					return err
+				}
+			}
 			params.EventNames = &eventNames
 		}
 
-		jsonData, err := json.Marshal(params)
+		c, err := config.NewClient()
 		if err != nil {
-			fmt.Println("Error marshalling JSON:", err)
-			return
+			return err
 		}
-
-		url := fmt.Sprintf("%s/v1/contracts", config.GetHost()) // Use the global host variable
-
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+		created, err := c.AddContract(cmd.Context(), params)
 		if err != nil {
-			fmt.Printf("Error creating post request %s:", err)
-			return
+			return fmt.Errorf("failed to add contract %s: %w", contractAddress, err)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+config.GetApiKey())
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			fmt.Println("Error making POST request:", err)
-			return
+		if !created {
+			fmt.Printf("Contract already added %s\n", contractAddress)
+		} else {
+			fmt.Printf("Successfully added contract %s\n", contractAddress)
 		}
-		defer resp.Body.Close()
 
-		if !(resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK) {
-			fmt.Println("Failed to create event listener", resp.Status)
-			return
+		// keep the ABI for decoding logs offline
+		if rawABI != "" {
+			stored := &config.StoredABI{Chain: chain, Address: contractAddress, Name: contractName, ABI: []byte(rawABI)}
+			if err := config.SaveABI(stored); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: ABI not stored locally: %s\n", err)
+			}
 		}
+		return nil
+	},
+}
 
-		if resp.StatusCode == http.StatusOK {
-			fmt.Printf("Contract already added %s", contractAddress)
-			return
+// checkEventNames verifies that every name of the comma separated list
+// names is an event of the ABI
+func checkEventNames(a *decode.ABI, names string) error {
+	var missing []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" && !a.HasEvent(name) {
+			missing = append(missing, name)
 		}
-
-		fmt.Printf("Successfully added contract %s", contractAddress)
-	},
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("events not in ABI: %s (available: %s)", strings.Join(missing, ", "), strings.Join(a.EventNames(), ", "))
+	}
+	return nil
 }
 
 func init() {
 	AddCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum, required)")
 	AddCmd.Flags().StringVarP(&network, "network", "n", "mainnet", "Blockchain network (eg. mainnet, required)")
 	AddCmd.Flags().StringVarP(&rawABI, "abi", "r", "", "Raw ABI (optional)")
-	AddCmd.Flags().StringVarP(&rawABIFile, "abi_file", "f", "", "Raw ABI file path (optional)")
+	AddCmd.Flags().StringVarP(&rawABIFile, "abi_file", "f", "", "ABI or Foundry/Hardhat artifact file path (optional)")
+	AddCmd.Flags().StringVarP(&eventNames, "event-names", "e", "", "Comma separated events to index, checked against the ABI (optional)")
 }
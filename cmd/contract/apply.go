@@ -0,0 +1,170 @@
+package contract
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/manifest"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var manifestFile string
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Register the contracts listed in a manifest",
+	Long: `Register the contracts listed in a manifest file. The manifest is compared
+with the contracts already indexed and only missing contracts are added, so
+applying it again changes nothing. Indexed contracts whose name or events
+differ from the manifest are reported but not modified.
+
+The contracts to add are listed and confirmed before any is created; pass
+--yes to skip the prompt, eg. in scripts.
+
+Manifest:
+  project: my-dapp          # sent as the project name of every contract
+  chain: ethereum           # default chain and network of the contracts
+  network: mainnet
+  contracts:
+    - name: WETH
+      address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+      abi: abi/weth.json    # ABI or build artifact, relative to the manifest
+      events: [Transfer, Deposit]
+    - name: USDbC
+      chain: base
+      address: "0xd9aAEc86B65D86f6A7B5B1b0c42FFA531710b6CA"
+
+Examples:
+  heimdahl contract apply -f contracts.yaml --dry-run
+  heimdahl contract apply -f contracts.yaml
+  heimdahl contract apply -f contracts.yaml --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := manifest.Read(manifestFile)
+		if err != nil {
+			return err
+		}
+		for _, c := range m.Contracts {
+			if err := checkManifestEvents(c); err != nil {
+				return fmt.Errorf("%s: %w", c.Name, err)
+			}
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		var indexed []lib.ContractInfo
+		for _, n := range m.Networks() {
+			contracts, err := c.ListContracts(cmd.Context(), n[0], n[1])
+			if err != nil {
+				return fmt.Errorf("failed to list contracts on %s %s: %w", n[0], n[1], err)
+			}
+			indexed = append(indexed, contracts...)
+		}
+		steps := m.Plan(indexed)
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			var create []string
+			for _, step := range steps {
+				if step.Action == manifest.Create {
+					mc := step.Contract
+					create = append(create, fmt.Sprintf("  %s %s %s %s", mc.Chain, mc.Network, mc.Name, mc.Address))
+				}
+			}
+			if len(create) > 0 {
+				// the table is only written once contracts are added, so
+				// show what the prompt is about first
+				if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+					fmt.Fprintf(os.Stderr, "Contracts to add:\n%s\n", strings.Join(create, "\n"))
+				}
+				action := fmt.Sprintf("add %d contracts", len(create))
+				if len(create) == 1 {
+					action = "add 1 contract"
+				}
+				if err := confirm(cmd, action); err != nil {
+					return err
+				}
+			}
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+
+		counts := map[manifest.Action]int{}
+		failed := 0
+		for _, step := range steps {
+			counts[step.Action]++
+			status := string(step.Action)
+			switch {
+			case step.Action == manifest.Differs:
+				status += ": " + strings.Join(step.Changes, "; ")
+			case step.Action == manifest.Create && !dryRun:
+				params := step.Contract.Params(m.Project)
+				if status, err = register(cmd.Context(), c, params, step.Contract.ABI); err != nil {
+					status = "failed: " + err.Error()
+					failed++
+				}
+			}
+
+			rec := format.Record{
+				{Name: "chain", Value: step.Contract.Chain},
+				{Name: "network", Value: step.Contract.Network},
+				{Name: "name", Value: step.Contract.Name},
+				{Name: "address", Value: step.Contract.Address},
+				{Name: "status", Value: status},
+			}
+			if err := r.Write(rec); err != nil {
+				return err
+			}
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+
+		verb := "added"
+		if dryRun {
+			verb = "to add"
+		}
+		fmt.Fprintf(os.Stderr, "%d %s, %d unchanged, %d differ\n", counts[manifest.Create]-failed, verb, counts[manifest.Unchanged], counts[manifest.Differs])
+		if failed > 0 {
+			return fmt.Errorf("failed to add %d of %d contracts", failed, counts[manifest.Create])
+		}
+		return nil
+	},
+}
+
+// checkManifestEvents verifies the events of a manifest contract against
+// its ABI, or the ABI stored for it when the manifest gives none
+func checkManifestEvents(c manifest.Contract) error {
+	var contractABI *decode.ABI
+	if len(c.ABI) > 0 {
+		parsed, err := decode.ParseABI(c.ABI)
+		if err != nil {
+			return err
+		}
+		contractABI = parsed
+	} else if stored, err := config.LoadABI(c.Chain, c.Address); err == nil {
+		if contractABI, err = decode.ParseABI(stored.ABI); err != nil {
+			return fmt.Errorf("stored ABI of %s: %w", c.Address, err)
+		}
+	}
+	if contractABI == nil || len(c.Events) == 0 {
+		return nil
+	}
+	return checkEventNames(contractABI, strings.Join(c.Events, ","))
+}
+
+func init() {
+	ApplyCmd.Flags().StringVarP(&manifestFile, "file", "f", "", "Contract manifest file (required)")
+	ApplyCmd.Flags().Bool("dry-run", false, "Show the plan without adding contracts")
+	ApplyCmd.Flags().BoolP("yes", "y", false, "Add contracts without asking for confirmation")
+	ApplyCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,36 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+// errAborted is returned when a confirmation prompt is declined
+var errAborted = errors.New("aborted")
+
+// confirm asks to go ahead with action unless --yes was given. Without a
+// terminal to ask on it fails instead of guessing.
+func confirm(cmd *cobra.Command, action string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("cannot confirm %s without a terminal, pass --yes", action)
+	}
+
+	prompt := promptui.Prompt{Label: strings.ToUpper(action[:1]) + action[1:], IsConfirm: true}
+	if _, err := prompt.Run(); err != nil {
+		if errors.Is(err, promptui.ErrAbort) || errors.Is(err, promptui.ErrInterrupt) {
+			return errAborted
+		}
+		if errors.Is(err, promptui.ErrEOF) {
+			return fmt.Errorf("no confirmation to %s, pass --yes", action)
+		}
+		return fmt.Errorf("read confirmation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+)
+
+var EventsCmd = &cobra.Command{
+	Use:   "events [address]",
+	Short: "List the events of a contract with their signatures and topic hashes",
+	Long: `List every event of a contract ABI with its canonical signature, topic0 hash
+and its indexed and data arguments, eg. to check which events will be indexed
+before adding the contract.
+
+The ABI is read from --abi_file, or from the local ABI store for the contract
+address on --chain (ABIs given to "contract add" are stored there).
+
+Examples:
+  heimdahl contract events --abi_file weth.abi.json
+  heimdahl contract events 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var a *decode.ABI
+		var err error
+		switch {
+		case rawABIFile != "":
+			a, err = decode.LoadABI(rawABIFile)
+		case len(args) == 1:
+			a, err = storedABI(args[0])
+		default:
+			return errors.New("pass a contract address or --abi_file")
+		}
+		if err != nil {
+			return err
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		for _, e := range a.Events() {
+			if err := r.Write(decode.EventRecord(e)); err != nil {
+				return err
+			}
+		}
+		return r.Close()
+	},
+}
+
+// storedABI returns the ABI stored for address on chain
+func storedABI(address string) (*decode.ABI, error) {
+	stored, err := config.LoadABI(chain, address)
+	if err != nil {
+		if errors.Is(err, config.ErrABINotFound) {
+			return nil, fmt.Errorf(`%w, import it with "heimdahl abi import" or pass --abi_file`, err)
+		}
+		return nil, err
+	}
+	return decode.ParseABI(stored.ABI)
+}
+
+func init() {
+	EventsCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum)")
+	EventsCmd.Flags().StringVarP(&rawABIFile, "abi_file", "f", "", "ABI or Foundry/Hardhat artifact file path")
+}
@@ -0,0 +1,143 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var artifactsDir string
+
+var ImportCmd = &cobra.Command{
+	Use:   "import [broadcast-file|deployments-dir]",
+	Short: "Add every contract deployed by a Foundry or Hardhat project",
+	Long: `Add every contract deployed by a Foundry script or with hardhat-deploy, with
+the ABI of its build artifact.
+
+Arguments:
+  broadcast-file  - Foundry broadcast log, eg. broadcast/Deploy.s.sol/1/run-latest.json.
+                    ABIs are read from the project's out directory, see --artifacts.
+  deployments-dir - hardhat-deploy network directory, eg. deployments/mainnet
+
+The chain and network are taken from the chain id of the deployments unless
+--chain and --network are given. ABIs are also kept in the local ABI store.
+
+Examples:
+  heimdahl contract import broadcast/Deploy.s.sol/1/run-latest.json
+  heimdahl contract import deployments/mainnet --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := artifact.Read(args[0], artifactsDir)
+		if err != nil {
+			return err
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		if project.ChainID != 0 && !cmd.Flags().Changed("chain") && !cmd.Flags().Changed("network") {
+			if chain, network, err = chainByID(cmd.Context(), c, project.ChainID); err != nil {
+				return err
+			}
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		failed := 0
+		for _, d := range project.Deployments {
+			status := "would add"
+			if !dryRun {
+				if status, err = importDeployment(cmd.Context(), c, d); err != nil {
+					status = "failed: " + err.Error()
+					failed++
+				}
+			}
+
+			rec := format.Record{
+				{Name: "chain", Value: chain},
+				{Name: "network", Value: network},
+				{Name: "name", Value: d.Name},
+				{Name: "address", Value: d.Address},
+				{Name: "status", Value: status},
+			}
+			if err := r.Write(rec); err != nil {
+				return err
+			}
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to add %d of %d contracts", failed, len(project.Deployments))
+		}
+		return nil
+	},
+}
+
+// importDeployment adds a deployed contract and stores its ABI, returning
+// whether it was added or already known
+func importDeployment(ctx context.Context, c *client.Client, d artifact.Deployment) (string, error) {
+	rawABI := string(d.ABI)
+	params := lib.ContractParams{
+		Chain:           chain,
+		Network:         network,
+		ContractAddress: d.Address,
+		ContractName:    d.Name,
+		RawABI:          &rawABI,
+	}
+	return register(ctx, c, params, d.ABI)
+}
+
+// register adds a contract and keeps its ABI, if any, in the local ABI
+// store, returning whether it was added or already known
+func register(ctx context.Context, c *client.Client, params lib.ContractParams, rawABI json.RawMessage) (string, error) {
+	created, err := c.AddContract(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rawABI) > 0 {
+		stored := &config.StoredABI{Chain: params.Chain, Address: params.ContractAddress, Name: params.ContractName, ABI: rawABI}
+		if err := config.SaveABI(stored); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ABI of %s not stored locally: %s\n", params.ContractName, err)
+		}
+	}
+	if !created {
+		return "already added", nil
+	}
+	return "added", nil
+}
+
+// chainByID finds the chain and network with the given chain id
+func chainByID(ctx context.Context, c *client.Client, id int64) (string, string, error) {
+	chains, err := c.ListChains(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list chains: %w", err)
+	}
+	for _, ch := range chains {
+		if int64(ch.ChainID) == id {
+			return ch.Chain, ch.Network, nil
+		}
+	}
+	return "", "", fmt.Errorf("chain id %d is not supported, pass --chain and --network", id)
+}
+
+func init() {
+	ImportCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum)")
+	ImportCmd.Flags().StringVarP(&network, "network", "n", "mainnet", "Blockchain network (eg. mainnet)")
+	ImportCmd.Flags().StringVar(&artifactsDir, "artifacts", "", "Foundry out directory with the contract artifacts (default <project>/out)")
+	ImportCmd.Flags().Bool("dry-run", false, "Show the contracts that would be added without adding them")
+}
@@ -1,12 +1,9 @@
 package contract
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
-	"io"
-	"net/http"
-	"strings"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -14,61 +11,31 @@ import (
 var ListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all contracts",
-	Run: func(cmd *cobra.Command, args []string) {
-		url := fmt.Sprintf("%s/v1/contracts?chain=%s&network=%s", config.GetHost(), chain, network) // Use the global host variable
-
-		req, err := http.NewRequest("GET", url, nil)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.NewClient()
 		if err != nil {
-			fmt.Println("Error creating GET request:", err)
-			return
+			return err
 		}
-
-		req.Header.Set("Authorization", "Bearer "+config.GetApiKey())
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
+		contractInfos, err := c.ListContracts(cmd.Context(), chain, network)
 		if err != nil {
-			fmt.Println("Error making GET request:", err)
-			return
+			return fmt.Errorf("failed to list contracts: %w", err)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("Failed to list event listeners: %s\n", resp.Status)
-			return
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println("Error reading response body:", err)
-			return
+		if len(contractInfos) == 0 {
+			fmt.Fprintf(os.Stderr, "Could not find indexed contracts for %s network %s\n", chain, network)
+			return nil
 		}
 
-		var contractInfos []ContractInfo
-		err = json.Unmarshal(body, &contractInfos)
+		r, err := config.NewRenderer(false)
 		if err != nil {
-			fmt.Println("Error unmarshalling JSON:", err)
-			return
+			return err
 		}
-
-		if len(contractInfos) == 0 {
-			fmt.Printf("Could not find indexed contracts for %s network %s\n", chain, network)
-			return
-		}
-
-		// Then data rows
 		for _, contractInfo := range contractInfos {
-			fmt.Printf("Chain:            %s\n", contractInfo.Chain)
-			fmt.Printf("Network:          %s\n", contractInfo.Network)
-			fmt.Printf("Contract Identifier:    %s\n", contractInfo.ContractName)
-			fmt.Printf("Contract Address: %s\n", contractInfo.ContractAddress)
-			fmt.Printf("Events:\n")
-			for _, event := range strings.Split(contractInfo.Events, ",") {
-				fmt.Printf("  - %s\n", strings.TrimSpace(event))
+			if err := r.Write(contractInfo.Record()); err != nil {
+				return err
 			}
-			fmt.Println(strings.Repeat("-", 80)) // Add a separator for better readability
 		}
-		fmt.Println()
+		return r.Close()
 	},
 }
 
@@ -0,0 +1,58 @@
+package contract
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var RemoveCmd = &cobra.Command{
+	Use:   "remove [address]",
+	Short: "Stop indexing a contract",
+	Long: `Remove a registered contract so its events are no longer indexed. The contract
+is shown and the removal must be confirmed unless --yes is given. Its ABI is
+kept in the local ABI store, see "heimdahl abi remove".
+
+Examples:
+  heimdahl contract remove 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+  heimdahl contract remove 0xd9aAEc86B65D86f6A7B5B1b0c42FFA531710b6CA -c base --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractAddress := args[0]
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		current, err := c.GetContract(cmd.Context(), chain, network, contractAddress)
+		if err != nil {
+			return fmt.Errorf("failed to get contract %s: %w", contractAddress, err)
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		if err := r.Write(current.Record()); err != nil {
+			return err
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+
+		if err := confirm(cmd, fmt.Sprintf("remove contract %s from %s %s", contractAddress, chain, network)); err != nil {
+			return err
+		}
+		if err := c.RemoveContract(cmd.Context(), chain, network, contractAddress); err != nil {
+			return fmt.Errorf("failed to remove contract %s: %w", contractAddress, err)
+		}
+		fmt.Printf("Removed contract %s\n", contractAddress)
+		return nil
+	},
+}
+
+func init() {
+	RemoveCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum, required)")
+	RemoveCmd.Flags().StringVarP(&network, "network", "n", "mainnet", "Blockchain network (eg. mainnet, required)")
+	RemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+}
@@ -19,5 +19,10 @@ func init() {
 	ContractCmd.AddCommand(AddCmd)
 	ContractCmd.AddCommand(ShowCmd)
 	ContractCmd.AddCommand(ListCmd)
+	ContractCmd.AddCommand(EventsCmd)
+	ContractCmd.AddCommand(ImportCmd)
+	ContractCmd.AddCommand(ApplyCmd)
+	ContractCmd.AddCommand(UpdateCmd)
+	ContractCmd.AddCommand(RemoveCmd)
 
 }
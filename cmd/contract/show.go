@@ -4,80 +4,140 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
-	"io"
-	"net/http"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"os"
 
 	"github.com/spf13/cobra"
 )
 
-type ContractInfo struct {
-	Chain           string `json:"chain"`
-	Network         string `json:"network"`
-	ContractName    string `json:"contract_name"`
-	ContractAddress string `json:"contract_address"`
-	Events          string `json:"events"`
-	ABI             string `json:"-"`
-}
-
-var address string
-
 var ShowCmd = &cobra.Command{
 	Use:   "show [address]",
 	Short: "Show contract by address",
-	Long: `Show contract metadata by address. 
-		Usage: heimdahl contract show 0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2`,
-
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) != 1 {
-			fmt.Println("Usage: heimdahl contract show [address]")
-			return
-		}
+	Long: `Show a contract registered on a chain network with the events of its ABI, their
+topic hashes and whether they are indexed. The ABI registered with the contract
+is used, or the one in the local ABI store when the API returns none.
+
+With --output json or yaml the contract is written as one document holding
+the events and the ABI.
+
+Examples:
+  heimdahl contract show 0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2
+  heimdahl contract show 0xd9aAEc86B65D86f6A7B5B1b0c42FFA531710b6CA -c base -o yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		address := args[0]
 
-		url := fmt.Sprintf("%s/v1/contracts/%s", config.GetHost(), address) // Use the global host variable
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		c, err := config.NewClient()
 		if err != nil {
-			fmt.Println("Error making GET request:", err)
-			return
+			return err
+		}
+		contractInfo, err := c.GetContract(cmd.Context(), chain, network, address)
+		if err != nil {
+			return fmt.Errorf("failed to get contract %s: %w", address, err)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+config.GetApiKey())
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
+		raw, err := contractInfo.RawABI()
 		if err != nil {
-			fmt.Println("Error performing request:", err)
-			return
+			return err
+		}
+		if raw == nil {
+			if stored, err := config.LoadABI(chain, address); err == nil {
+				raw = stored.ABI
+			}
 		}
+		var contractABI *decode.ABI
+		if raw != nil {
+			if contractABI, err = decode.ParseABI(raw); err != nil {
+				return fmt.Errorf("invalid ABI of %s: %w", address, err)
+			}
+		}
+		events := eventStatus(contractInfo, contractABI)
 
-		defer resp.Body.Close()
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
 
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("Failed to get event listener: %s\n", resp.Status)
-			return
+		rec := contractInfo.Record()
+		if format.Nested(config.Config.Output) {
+			if contractABI != nil {
+				var v interface{}
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return fmt.Errorf("invalid ABI of %s: %w", address, err)
+				}
+				rec = append(rec, format.Field{Name: "abi_events", Value: events}, format.Field{Name: "abi", Value: v})
+			}
+			if err := r.Write(rec); err != nil {
+				return err
+			}
+			return r.Close()
 		}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println("Error reading response body:", err)
-			return
+		if err := r.Write(rec); err != nil {
+			return err
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+		if contractABI == nil {
+			fmt.Fprintf(os.Stderr, "No ABI known for %s, import it with \"heimdahl abi import\"\n", address)
+			return nil
 		}
 
-		var contractInfo ContractInfo
-		err = json.Unmarshal(body, &contractInfo)
+		// the event table has its own columns, --fields and --sort select
+		// those of the contract
+		fmt.Println()
+		er, err := format.New(config.Config.Output, os.Stdout, format.Options{NoHeader: config.Config.NoHeader})
 		if err != nil {
-			fmt.Println("Error unmarshalling JSON:", err)
-			return
+			return err
 		}
-
-		fmt.Printf("Network: %s\nContract Identifier: %s\nContract Address: %s\nEvents: %s",
-			contractInfo.Network,
-			contractInfo.ContractName,
-			contractInfo.ContractAddress,
-			contractInfo.Events)
+		for _, e := range events {
+			if err := er.Write(e.(format.Record)); err != nil {
+				return err
+			}
+		}
+		return er.Close()
 	},
 }
 
-func init() {
+// eventStatus describes the events of the contract's ABI and whether they
+// are indexed, followed by indexed events missing from the ABI
+func eventStatus(info *lib.ContractInfo, contractABI *decode.ABI) []interface{} {
+	if contractABI == nil {
+		return nil
+	}
+	indexed := map[string]bool{}
+	for _, name := range info.EventNames() {
+		indexed[name] = true
+	}
+
+	var events []interface{}
+	for _, e := range contractABI.Events() {
+		status := "not indexed"
+		if indexed[e.RawName] {
+			status = "indexed"
+			delete(indexed, e.RawName)
+		}
+		events = append(events, append(decode.EventRecord(e), format.Field{Name: "status", Value: status}))
+	}
+	for _, name := range info.EventNames() {
+		if indexed[name] {
+			events = append(events, format.Record{
+				{Name: "event", Value: name},
+				{Name: "signature", Value: nil},
+				{Name: "topic0", Value: nil},
+				{Name: "indexed", Value: nil},
+				{Name: "data", Value: nil},
+				{Name: "status", Value: "indexed, not in ABI"},
+			})
+		}
+	}
+	return events
+}
 
+func init() {
+	ShowCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum)")
+	ShowCmd.Flags().StringVarP(&network, "network", "n", "mainnet", "Blockchain network (eg. mainnet)")
 }
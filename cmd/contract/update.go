@@ -0,0 +1,169 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var newName string
+
+var UpdateCmd = &cobra.Command{
+	Use:   "update [address]",
+	Short: "Change the name, ABI or events of a contract",
+	Long: `Change a registered contract: rename it, replace its ABI or change the events
+to index. Only the given fields are changed. The changes are shown and must be
+confirmed unless --yes is given.
+
+Examples:
+  heimdahl contract update 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2 --abi_file weth.abi.json
+  heimdahl contract update 0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2 -e Transfer,Deposit --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contractAddress := args[0]
+
+		var update lib.ContractUpdate
+		if cmd.Flags().Changed("name") {
+			if strings.TrimSpace(newName) == "" {
+				return errors.New("--name must not be empty")
+			}
+			update.ContractName = &newName
+		}
+
+		if rawABIFile != "" {
+			abb, err := os.ReadFile(rawABIFile)
+			if err != nil {
+				return fmt.Errorf("error reading ABI file: %w", err)
+			}
+			rawABI = string(abb)
+		}
+		var contractABI *decode.ABI
+		if rawABI != "" {
+			raw, err := artifact.ABI([]byte(rawABI))
+			if err != nil {
+				return fmt.Errorf("error parsing ABI: %w", err)
+			}
+			rawABI = string(raw)
+			if contractABI, err = decode.ParseABI(raw); err != nil {
+				return err
+			}
+			update.RawABI = &rawABI
+		}
+
+		if cmd.Flags().Changed("event-names") {
+			if contractABI == nil {
+				if stored, err := storedABI(contractAddress); err == nil {
+					contractABI = stored
+				}
+			}
+			if contractABI != nil {
+				if err := checkEventNames(contractABI, eventNames); err != nil {
+					return err
+				}
+			}
+			update.EventNames = &eventNames
+		}
+
+		if update == (lib.ContractUpdate{}) {
+			return errors.New("nothing to update, pass --name, --abi, --abi_file or --event-names")
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		current, err := c.GetContract(cmd.Context(), chain, network, contractAddress)
+		if err != nil {
+			return fmt.Errorf("failed to get contract %s: %w", contractAddress, err)
+		}
+
+		changes := contractChanges(current, update, contractABI)
+		if len(changes) == 0 {
+			fmt.Printf("Contract %s is up to date\n", contractAddress)
+			return nil
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+		for _, rec := range changes {
+			if err := r.Write(rec); err != nil {
+				return err
+			}
+		}
+		if err := r.Close(); err != nil {
+			return err
+		}
+
+		if err := confirm(cmd, "update contract "+contractAddress); err != nil {
+			return err
+		}
+		if err := c.UpdateContract(cmd.Context(), chain, network, contractAddress, update); err != nil {
+			return fmt.Errorf("failed to update contract %s: %w", contractAddress, err)
+		}
+		fmt.Printf("Updated contract %s\n", contractAddress)
+
+		if update.RawABI != nil {
+			name := current.ContractName
+			if update.ContractName != nil {
+				name = newName
+			}
+			stored := &config.StoredABI{Chain: chain, Address: contractAddress, Name: name, ABI: []byte(rawABI)}
+			if err := config.SaveABI(stored); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: ABI not stored locally: %s\n", err)
+			}
+		}
+		return nil
+	},
+}
+
+// contractChanges describes what update changes in the current contract as
+// field, before and after records, leaving out fields it leaves as they are
+func contractChanges(current *lib.ContractInfo, update lib.ContractUpdate, contractABI *decode.ABI) []format.Record {
+	var changes []format.Record
+	change := func(field, before, after string) {
+		changes = append(changes, format.Record{
+			{Name: "field", Value: field},
+			{Name: "before", Value: before},
+			{Name: "after", Value: after},
+		})
+	}
+
+	if update.ContractName != nil && *update.ContractName != current.ContractName {
+		change("name", current.ContractName, *update.ContractName)
+	}
+	if update.EventNames != nil {
+		after := lib.ContractInfo{Events: *update.EventNames}.EventNames()
+		if strings.Join(after, ",") != strings.Join(current.EventNames(), ",") {
+			change("events", strings.Join(current.EventNames(), ", "), strings.Join(after, ", "))
+		}
+	}
+	if contractABI != nil {
+		before := ""
+		if raw, err := current.RawABI(); err == nil && raw != nil {
+			if a, err := decode.ParseABI(raw); err == nil {
+				before = fmt.Sprintf("%d events", len(a.Events()))
+			}
+		}
+		change("abi", before, fmt.Sprintf("replaced, %d events", len(contractABI.Events())))
+	}
+	return changes
+}
+
+func init() {
+	UpdateCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name (eg. ethereum, required)")
+	UpdateCmd.Flags().StringVarP(&network, "network", "n", "mainnet", "Blockchain network (eg. mainnet, required)")
+	UpdateCmd.Flags().StringVar(&newName, "name", "", "New contract name")
+	UpdateCmd.Flags().StringVarP(&rawABI, "abi", "r", "", "Raw ABI replacing the contract's ABI")
+	UpdateCmd.Flags().StringVarP(&rawABIFile, "abi_file", "f", "", "ABI or Foundry/Hardhat artifact file replacing the contract's ABI")
+	UpdateCmd.Flags().StringVarP(&eventNames, "event-names", "e", "", "Comma separated events to index, checked against the ABI")
+	UpdateCmd.Flags().BoolP("yes", "y", false, "Update without asking for confirmation")
+}
@@ -0,0 +1,143 @@
+package decode
+
+import (
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	chain   string
+	address string
+	abiFile string
+)
+
+var LogCmd = &cobra.Command{
+	Use:   "log [file]",
+	Short: "Decode raw event logs with a contract ABI",
+	Long: `Decode raw event logs offline, eg. logs fetched from your own node.
+
+The input is read from file, or stdin when file is omitted or -. It may hold a
+single log object, an array of logs as returned by eth_getLogs, a transaction
+receipt or a JSON-RPC response wrapping any of these. Each log needs its topics
+and data, the remaining fields are shown when present.
+
+Logs are decoded with the ABI stored for their address on --chain (see
+"heimdahl abi import"), or with --abi. Events are shown with the same fields as
+"heimdahl event list".
+
+Example:
+  cast receipt 0x... --json | heimdahl decode log -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = os.Stdin
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		logs, err := decode.ReadLogs(in)
+		if err != nil {
+			return err
+		}
+
+		var fixed *decode.ABI
+		if abiFile != "" {
+			if fixed, err = decode.LoadABI(abiFile); err != nil {
+				return err
+			}
+		}
+
+		r, err := config.NewRenderer(false)
+		if err != nil {
+			return err
+		}
+
+		// logs of a receipt usually share a few contracts
+		abis := map[string]*decode.ABI{}
+		for i, l := range logs {
+			contract := address
+			if contract == "" && l.Address != nil {
+				contract = l.Address.Hex()
+			}
+
+			a := fixed
+			if a == nil {
+				if contract == "" {
+					return fmt.Errorf("log %d has no address, use --address or --abi", i)
+				}
+				key := strings.ToLower(contract)
+				if a = abis[key]; a == nil {
+					stored, err := config.LoadABI(chain, contract)
+					if err != nil {
+						return fmt.Errorf("log %d: %w", i, err)
+					}
+					if a, err = decode.ParseABI(stored.ABI); err != nil {
+						return fmt.Errorf("log %d: %w", i, err)
+					}
+					abis[key] = a
+				}
+			}
+
+			event, err := logEvent(a, l, contract)
+			if err != nil {
+				return fmt.Errorf("log %d: %w", i, err)
+			}
+			if err := r.Write(event.Record()); err != nil {
+				return err
+			}
+		}
+		return r.Close()
+	},
+}
+
+// logEvent decodes a raw log into an event with the envelope fields the log
+// carries
+func logEvent(a *decode.ABI, l decode.Log, contract string) (lib.Event, error) {
+	e, args, err := a.Log(l)
+	if err != nil {
+		return lib.Event{}, err
+	}
+
+	fields := map[string]interface{}{"chain": chain, "event": e.RawName}
+	if contract != "" {
+		fields["contractAddress"] = contract
+	}
+	if l.BlockNumber != nil {
+		fields["blockNumber"] = l.BlockNumber.String()
+	}
+	if l.BlockHash != nil {
+		fields["blockHash"] = l.BlockHash.Hex()
+	}
+	if l.TxHash != nil {
+		fields["transactionHash"] = l.TxHash.Hex()
+	}
+	if l.TxIndex != nil {
+		fields["transactionIndex"] = l.TxIndex.String()
+	}
+	if l.Index != nil {
+		fields["logIndex"] = l.Index.String()
+	}
+
+	event, err := lib.NewPartialEvent(fields)
+	if err != nil {
+		return lib.Event{}, err
+	}
+	event.Args = args
+	return event, nil
+}
+
+func init() {
+	LogCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain name used to look up stored ABIs")
+	LogCmd.Flags().StringVar(&address, "address", "", "Contract address, overrides the address of the logs")
+	LogCmd.Flags().StringVar(&abiFile, "abi", "", "Contract ABI file to decode with instead of the ABI store")
+}
@@ -0,0 +1,14 @@
+package decode
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var DecodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Decode raw blockchain data offline",
+}
+
+func init() {
+	DecodeCmd.AddCommand(LogCmd)
+}
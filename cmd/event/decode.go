@@ -0,0 +1,104 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/decode"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// decoder checks events for list and subscribe and types their arguments
+// with the ABI given with --abi, or else the ABI stored for the contract of
+// each pattern. Invalid events are skipped unless --strict is set.
+type decoder struct {
+	patterns map[string]patternABI
+	strict   bool
+	warned   bool
+}
+
+// patternABI is what the decoder knows about the events of one pattern
+type patternABI struct {
+	// name types events that do not carry their name
+	name string
+	abi  *decode.ABI
+}
+
+func newDecoder(cmd *cobra.Command, patterns ...string) (*decoder, error) {
+	d := &decoder{patterns: map[string]patternABI{}}
+	d.strict, _ = cmd.Flags().GetBool("strict")
+
+	var fixed *decode.ABI
+	path, _ := cmd.Flags().GetString("abi")
+	if path != "" {
+		a, err := decode.LoadABI(path)
+		if err != nil {
+			return nil, err
+		}
+		fixed = a
+	}
+
+	for _, p := range patterns {
+		e, err := pattern.ParseEvent(p)
+		if err != nil {
+			// the server reports malformed patterns
+			continue
+		}
+		a := fixed
+		if a == nil {
+			if a, err = storedABI(e); err != nil {
+				return nil, err
+			}
+		}
+		d.patterns[p] = patternABI{name: e.Event, abi: a}
+	}
+	return d, nil
+}
+
+// storedABI returns the ABI in the local store for the contract of an event
+// pattern, nil when the pattern matches any contract or none is stored
+func storedABI(e pattern.Event) (*decode.ABI, error) {
+	if e.Chain == pattern.Wildcard || e.Address == pattern.Wildcard {
+		return nil, nil
+	}
+	stored, err := config.LoadABI(e.Chain, e.Address)
+	if errors.Is(err, config.ErrABINotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a, err := decode.ParseABI(stored.ABI)
+	if err != nil {
+		return nil, fmt.Errorf("stored ABI of %s %s: %w", e.Chain, e.Address, err)
+	}
+	return a, nil
+}
+
+// event takes the result of decoding an event received for pattern p. It
+// reports false when the event is invalid and must be skipped, or returns
+// the error with --strict.
+func (d *decoder) event(e *lib.Event, err error, p string) (bool, error) {
+	if err != nil {
+		if d.strict {
+			return false, err
+		}
+		if !d.warned {
+			fmt.Fprintf(os.Stderr, "skipping %s (use --strict to fail instead)\n", err)
+			d.warned = true
+		}
+		return false, nil
+	}
+
+	if pa := d.patterns[p]; pa.abi != nil {
+		name := pa.name
+		if e.Name != "" {
+			name = e.Name
+		}
+		pa.abi.Args(name, e.Args)
+	}
+	return true, nil
+}
@@ -0,0 +1,99 @@
+package event
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/spf13/cobra"
+)
+
+const (
+	token   = "0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2"
+	holder  = "0x52908400098527886e0f7030069857d2e4169ee7"
+	checked = "0x52908400098527886E0F7030069857D2E4169EE7"
+)
+
+var erc20 = json.RawMessage(`[{"type": "event", "name": "Transfer", "inputs": [
+	{"name": "src", "type": "address", "indexed": true},
+	{"name": "dst", "type": "address", "indexed": true},
+	{"name": "wad", "type": "uint256"}]}]`)
+
+func testCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("strict", false, "")
+	cmd.Flags().String("abi", "", "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+// decoded returns the src argument of a Transfer received for pattern p
+func decoded(t *testing.T, d *decoder, p string) interface{} {
+	t.Helper()
+	e, err := lib.DecodeEvent([]byte(`{"blockNumber": 7, "transactionHash": "0xaa", "src": "` + holder + `", "wad": 1}`))
+	if ok, err := d.event(&e, err, p); !ok {
+		t.Fatalf("event skipped: %v", err)
+	}
+	return e.Args["src"]
+}
+
+func TestDecoderUsesStoredABI(t *testing.T) {
+	t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+	if err := config.SaveABI(&config.StoredABI{Chain: "ethereum", Address: token, ABI: erc20}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := "ethereum.mainnet." + token + ".Transfer"
+	other := "ethereum.mainnet.0x0000000000000000000000000000000000000001.Transfer"
+	wild := "ethereum.mainnet.all.Transfer"
+	d, err := newDecoder(testCommand(t), stored, other, wild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded(t, d, stored); got != checked {
+		t.Errorf("src = %v, want checksummed %s", got, checked)
+	}
+	for _, p := range []string{other, wild} {
+		if got := decoded(t, d, p); got != holder {
+			t.Errorf("%s: src = %v, want it unchanged without a stored ABI", p, got)
+		}
+	}
+}
+
+func TestDecoderPrefersABIFlag(t *testing.T) {
+	t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+	// the stored ABI no longer matches the contract
+	stale := json.RawMessage(`[{"type": "event", "name": "Transfer", "inputs": [{"name": "src", "type": "bytes32"}]}]`)
+	if err := config.SaveABI(&config.StoredABI{Chain: "ethereum", Address: token, ABI: stale}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "erc20.json")
+	if err := os.WriteFile(path, erc20, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := "ethereum.mainnet." + token + ".Transfer"
+	d, err := newDecoder(testCommand(t, "--abi", path), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded(t, d, p); got != checked {
+		t.Errorf("src = %v, want %s typed by --abi", got, checked)
+	}
+}
+
+func TestDecoderRejectsCorruptStoredABI(t *testing.T) {
+	t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+	if err := config.SaveABI(&config.StoredABI{Chain: "ethereum", Address: token, ABI: json.RawMessage(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newDecoder(testCommand(t), "ethereum.mainnet."+token+".Transfer"); err == nil {
+		t.Error("newDecoder accepted an unusable stored ABI")
+	}
+}
@@ -1,176 +1,157 @@
 package event
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/filter"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/timerange"
 	"github.com/spf13/cobra"
-	"io"
-	"log"
-	"net/http"
-	"sort"
-	"strconv"
-	"strings"
+	"os"
 )
 
-var (
-	chain   string
-	network string
-)
-
-type EventMeta struct {
-	Chain   string `json:"chain"`
-	ChainID int    `json:"chain_id"`
-	Address string `json:"addresss"` // Note: "addresss" has a typo; update field name accordingly if needed.
-	Event   string `json:"event"`
-	Page    int    `json:"page"`
-	PerPage int    `json:"per_page"`
-	Total   int    `json:"total"`
-}
+var listPattern *pattern.Flags
+var listRange *timerange.Flags
+var listWhere *filter.Flags
 
-type EventData struct {
-	Chain   string `json:"chain"`
-	ChainID int    `json:"chain_id"`
-	Address string `json:"addresss"` // Note: "addresss" has a typo; update field name accordingly if needed.
-	Event   string `json:"event"`
-	Page    int    `json:"page"`
-	PerPage int    `json:"per_page"`
-	Total   int    `json:"total"`
-}
+// SubscribeCmd represents the listen command
+var ListCmd = &cobra.Command{
+	Use:   "list [pattern]",
+	Short: "List events for contract",
+	Long: `List collected events for contract 
+Arguments:
+	pattern - The search pattern chain.network.address.Event (eg. ethereum.mainnet.0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2.Transfer)
 
-type EventDetails struct {
-	Meta    EventMeta                `json:"meta"`
-	Details []map[string]interface{} `json:"events"`
-}
+The pattern may instead be built with --chain, --network, --address and --event.
 
-// Assuming we have event as map[string]interface{}
-func renderReplayEventTable(event map[string]interface{}) {
-	// Format known fields
-	blkn := event["blockNumber"].(float64)
-	blockNum := strconv.FormatInt(int64(blkn), 10)
-	transactionHash := fmt.Sprintf("%-15s", event["transactionHash"].(string))
-	timestamp := event["blockTimestamp"].(string)
-
-	// Collect remaining fields for event data
-	var eventData []string
-	for k, v := range event {
-		// Skip already used fields
-		if isReplayMetaField(k) {
-			continue
-		}
-		eventData = append(eventData, fmt.Sprintf("%s: %v", k, v))
-	}
+Use --all to export every page, or --limit to stop after a number of events.
+Rows are written as each page arrives.
 
-	// Print data row
-	fmt.Printf("%s | %s | %s | %s\n",
-		blockNum, timestamp, transactionHash, strings.Join(eventData, ", "))
-}
+--since/--until and --from-block/--to-block bound the results; they are sent
+to the server and also enforced locally. Results are returned newest first, so paging with --all
+stops once results are older than the range.
 
-func isReplayMetaField(field string) bool {
-	metaFields := map[string]bool{
-		"blockNumber":     true,
-		"blockTimestamp":  true,
-		"timestamp":       true,
-		"transactionHash": true,
-	}
-	return metaFields[field]
-}
+--where keeps only results matching an expression, eg. 'args.wad > 1000'.
+See "heimdahl help filters" for the syntax.
 
-// PrintEventDetails prints EventDetails in a clean, console-friendly format.
-// Keys in each event map are sorted alphabetically.
-func PrintEventDetails(e EventDetails) {
-	separator := strings.Repeat("-", 75)
-
-	// --- META SECTION ---
-	fmt.Println(separator)
-	fmt.Println("META")
-	fmt.Println(separator)
-
-	if len(e.Details) < 1 {
-		return
-	}
-
-	// NOTE: adapt these fields to your actual EventMeta struct.
-	fmt.Printf("Timestamp : %s\n", e.Details[0]["blockTimestamp"])
-	fmt.Printf("Page      : %d\n", e.Meta.Page)
-	fmt.Printf("Per Page  : %d\n", e.Meta.PerPage)
-	fmt.Printf("Total     : %d\n", e.Meta.Total)
-
-	// --- EVENTS SECTION ---
-	for _, event := range e.Details {
-		fmt.Println(separator)
-
-		// Collect keys
-		keys := make([]string, 0, len(event))
-		for k := range event {
-			keys = append(keys, k)
-		}
+Numbers are kept exact. Arguments are rendered by their ABI type: addresses as
+checksummed hex, bytes as hex and integers as exact decimals. The ABI is read
+from --abi, or else from the local ABI store (see "heimdahl abi") by the chain
+and address of the pattern.
 
-		// Sort alphabetically
-		sort.Strings(keys)
+Events missing their block number or transaction hash, or with malformed
+envelope fields, are skipped and counted. Use --strict to fail on them instead.`,
+	Args: cobra.MaximumNArgs(1),
 
-		// Print in order
-		for _, k := range keys {
-			fmt.Printf("%-10s: %v\n", k, event[k])
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, err := listPattern.Pattern(args)
+		if err != nil {
+			return err
 		}
-	}
-
-	fmt.Println(separator)
-}
 
-// SubscribeCmd represents the listen command
-var ListCmd = &cobra.Command{
-	Use:   "list [pattern]",
-	Short: "List events for contract",
-	Long: `List collected events for contract 
-Arguments:
-	pattern - The search pattern (required) (eg. ethereum.mainnet.0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2.Transfer)`,
-	Args: cobra.ExactArgs(1), // Expect exactly 2 arguments
+		rng, err := listRange.Range()
+		if err != nil {
+			return err
+		}
+		query := rng.Query()
 
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
-			cmd.Help()
-			return
+		where, err := listWhere.Expr()
+		if err != nil {
+			return err
 		}
 
-		pattern := args[0]
+		events, err := newDecoder(cmd, pattern)
+		if err != nil {
+			return err
+		}
 
 		page, _ := cmd.Flags().GetInt("page")
 		perpage, _ := cmd.Flags().GetInt("perPage")
-
-		// Prepare the WebSocket URL
-		httpUrl := fmt.Sprintf("%s/v1/events/list/%s?page=%d&pageSize=%d", config.GetHost(), pattern, page, perpage)
-		//log.Println("url ", httpUrl)
-		headers := make(http.Header)
-
-		headers.Set("Authorization", "Bearer "+config.GetApiKey())
-		headers.Set("Content-Type", "application/json")
-		req, _ := http.NewRequest(http.MethodGet, httpUrl, nil)
-
-		req.Header = headers
-		resp, err := http.DefaultClient.Do(req)
+		all, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		opts := client.PageOptions{StartPage: page, PerPage: perpage, All: all, Limit: limit, Concurrency: concurrency}
+		// invalid and filtered out events do not count towards --limit
+		opts.Filtered = true
+
+		r, err := config.NewRenderer(opts.Multipage())
 		if err != nil {
-			log.Fatalf("unable to retrieve events %s", err)
+			return err
 		}
 
-		b, err := io.ReadAll(resp.Body)
+		c, err := config.NewClient()
 		if err != nil {
-			log.Fatalf("unable to read body %s", err)
+			return err
+		}
+		count, total, skipped := 0, 0, 0
+		opts.AfterPage = func(_, t int) error {
+			total = t
+			return nil
+		}
+		err = client.Paginate(cmd.Context(), opts,
+			func(ctx context.Context, page int) ([]map[string]interface{}, int, error) {
+				details, err := c.ListEvents(ctx, pattern, client.ListOptions{Page: page, PerPage: perpage, Filter: query})
+				if err != nil {
+					return nil, 0, err
+				}
+				return details.Details, details.Meta.Total, nil
+			},
+			func(fields map[string]interface{}) error {
+				event, err := lib.NewEvent(fields)
+				if ok, err := events.event(&event, err, pattern); !ok {
+					if err != nil {
+						return err
+					}
+					skipped++
+					return client.ErrSkip
+				}
+
+				switch rng.Locate(event.Timestamp, event.BlockNumber) {
+				case timerange.Newer:
+					return client.ErrSkip
+				case timerange.Older:
+					return client.ErrStop
+				}
+				rec := event.Record()
+				matched, err := where.Match(rec)
+				if err != nil {
+					return err
+				}
+				if !matched {
+					return client.ErrSkip
+				}
+				count++
+				return r.Write(rec)
+			})
+
+		if cerr := r.Close(); err == nil {
+			err = cerr
 		}
-
-		var details EventDetails
-		err = json.Unmarshal(b, &details)
 		if err != nil {
-			log.Fatalf("unable to parse details %s", err)
+			return fmt.Errorf("unable to retrieve events: %w", err)
 		}
 
-		PrintEventDetails(details)
+		summary := fmt.Sprintf("%d of %d events", count, total)
+		if skipped > 0 {
+			summary += fmt.Sprintf(", %d invalid skipped", skipped)
+		}
+		fmt.Fprintln(os.Stderr, summary)
+		return nil
 	},
 }
 
 func init() {
-	ListCmd.Flags().StringVarP(&chain, "chain", "c", "ethereum", "Blockchain type  (eg. ethereum, required)")
-	ListCmd.Flags().StringVarP(&network, "network", "w", "mainnet", "Blockchain network (eg. mainnet, required)")
+	listWhere = filter.WhereFlag(ListCmd.Flags())
+	listRange = timerange.BlockFlags(ListCmd.Flags())
+	listPattern = pattern.EventFlags(ListCmd.Flags())
+	ListCmd.Flags().Bool("strict", false, "Fail on invalid events instead of skipping them")
+	ListCmd.Flags().String("abi", "", "Contract ABI file used to type event arguments, instead of the stored ABI")
 	ListCmd.Flags().IntP("page", "p", 0, "Page to replay")
 	ListCmd.Flags().IntP("perPage", "l", 20, "Events per page")
+	ListCmd.Flags().Bool("all", false, "Fetch all pages")
+	ListCmd.Flags().Int("limit", 0, "Maximum number of events to fetch across pages")
+	ListCmd.Flags().Int("concurrency", 1, "Number of pages to fetch in parallel with --all or --limit")
 }
@@ -1,120 +1,160 @@
 package event
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/gorilla/websocket"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/filter"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
 	"github.com/spf13/cobra"
-	"log"
-	"net/http"
+	"net/url"
+	"os"
 	"strconv"
-	"strings"
-	"time"
 )
 
-// Assuming we have event as map[string]interface{}
-func renderEventTable(event map[string]interface{}) {
-	log.Printf("%+v", event)
-	// Format known fields
-	blkn := event["blockNumber"].(float64)
-	blockNum := strconv.FormatInt(int64(blkn), 10)
-	blockHash := fmt.Sprintf("%-15s", event["blockHash"].(string))
-	timestamp := time.Unix(int64(event["blockTimestamp"].(float64)), 0).Format("2006-01-02 15:04:05")
-
-	var eventData []string
-	for k, v := range event {
-		// Skip already used fields
-		if isMetaField(k) {
-			continue
-		}
-		eventData = append(eventData, fmt.Sprintf("%s: %v", k, v))
-	}
-
-	fmt.Printf("| %s | %s | %s | %s\n",
-		blockNum, blockHash, timestamp, strings.Join(eventData, ", "))
-}
-
-func isMetaField(field string) bool {
-	metaFields := map[string]bool{
-		"chain":            true,
-		"network":          true,
-		"blockNumber":      true,
-		"blockHash":        true,
-		"blockTimestamp":   true,
-		"contractAddress":  true,
-		"timestamp":        true,
-		"transactionHash":  true,
-		"transactionIndex": true,
-	}
-	return metaFields[field]
-}
+var subscribePattern *pattern.Flags
+var subscribeWhere *filter.Flags
 
 // SubscribeCmd represents the listen command
 var SubscribeCmd = &cobra.Command{
-	Use:   "subscribe [pattern]",
+	Use:   "subscribe [pattern...]",
 	Short: "subscribe to realtime events for contract",
 	Long: `Subscribe to realtime events for contract 
 	Arguments:
-	  pattern - The search pattern (required) (eg. ethereum.mainnet.0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2.Transfer)`,
+	  pattern - The search pattern chain.network.address.Event (eg. ethereum.mainnet.0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2.Transfer)
 
-	Args: cobra.ExactArgs(1), // Expect exactly 2 arguments
+Several patterns may be given as arguments or in a file (--patterns-file, one per
+line). Each pattern gets its own connection and events are merged into one
+output in arrival order, tagged with the pattern that matched them.
 
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
-			cmd.Help()
-			return
-		}
+Without arguments the pattern is built from --chain, --network, --address and --event.
+Set on the command line, these flags also override segments of the patterns.
 
-		pattern := args[0]
+--where keeps only messages matching an expression, see "heimdahl help filters".
 
-		// Prepare the WebSocket URL
-		wsURL := fmt.Sprintf("%s/v1/events/stream/%s?api_key=%s", config.GetWsHost(), pattern, config.GetApiKey())
+Connections are kept alive with pings and re-established with backoff when they
+drop, resuming from the last received block without duplicates.
 
-		//log.Println("Connecting to WebSocket: ", wsURL)
+Numbers are kept exact. Arguments are rendered by their ABI type: addresses as
+checksummed hex, bytes as hex and integers as exact decimals. The ABI is read
+from --abi for every pattern, or else from the local ABI store (see "heimdahl
+abi") by the chain and address of each pattern.
 
-		//log.Println(wsURL)
-		headers := make(http.Header)
+Messages missing their block number or transaction hash, or with malformed
+envelope fields, are skipped and counted. Use --strict to fail on them instead.`,
 
-		headers.Set("Content-Type", "application/json")
+	Args: cobra.ArbitraryArgs,
 
-		conn, _, err := websocket.DefaultDialer.Dial(wsURL, headers)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patternsFile, _ := cmd.Flags().GetString("patterns-file")
+		patterns, err := pattern.FromArgs(args, patternsFile)
 		if err != nil {
-			log.Fatal("Error connecting to WebSocket:", err)
+			return err
 		}
-		defer conn.Close()
-
-		// Define headers
-		theaders := []string{"BLOCK#", "BLOCK_HASH", "TIMESTAMP", "CONTRACT", "TRANSACTION_HASH", "EVENT_DATA"}
-
-		// Print header row
-		fmt.Printf("%-8s | %-15s | %-19s | %-15s | %-15s | %s\n",
-			theaders[0],
-			theaders[1],
-			theaders[2],
-			theaders[3],
-			theaders[4],
-			theaders[5])
-
-		// Print separator
-		fmt.Println(strings.Repeat("-", 120))
-
-		// Listen for messages
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Println("Error reading message:", err)
-				return
+		if patterns, err = subscribePattern.Patterns(patterns); err != nil {
+			return err
+		}
+
+		where, err := subscribeWhere.Expr()
+		if err != nil {
+			return err
+		}
+
+		events, err := newDecoder(cmd, patterns...)
+		if err != nil {
+			return err
+		}
+
+		r, err := config.NewStreamRenderer()
+		if err != nil {
+			return err
+		}
+
+		// events are resumed from the last block, so each connection keeps a
+		// cursor skipping the events of that block which were already received
+		maxReconnects, _ := cmd.Flags().GetInt("max-reconnects")
+		cursors := make([]client.Cursor, len(patterns))
+		stats := make([]client.StreamStats, len(patterns))
+		streams := make([]client.StreamOptions, len(patterns))
+		for i, p := range patterns {
+			cursor := &cursors[i]
+			streams[i] = client.StreamOptions{
+				Path:          "/v1/events/stream/" + url.PathEscape(p),
+				Name:          p,
+				MaxReconnects: maxReconnects,
+				Stats:         &stats[i],
+				Resume: func() url.Values {
+					pos, ok := cursor.Resume()
+					if !ok {
+						return nil
+					}
+					return url.Values{"from_block": {strconv.FormatUint(pos, 10)}}
+				},
+			}
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		err = c.SubscribeAll(cmd.Context(), streams, func(i int, message []byte) error {
+			event, err := lib.DecodeEvent(message)
+			if ok, err := events.event(&event, err, patterns[i]); !ok {
+				if err != nil {
+					return err
+				}
+				stats[i].Invalid++
+				return nil
 			}
 
-			var event map[string]interface{}
-			err = json.Unmarshal(message, &event)
+			if !cursors[i].Next(event.BlockNumber, event.Key()) {
+				return nil
+			}
+
+			rec := event.Record()
+			if len(patterns) > 1 {
+				rec = append(format.Record{{Name: "pattern", Value: patterns[i]}}, rec...)
+			}
+			matched, err := where.Match(rec)
 			if err != nil {
-				log.Println("Error unmarshalling message:", err)
-				return
+				return err
+			}
+			if !matched {
+				return nil
 			}
-			//log.Printf("%+v", event)
-			renderEventTable(event)
+			if err := r.Write(rec); err != nil {
+				return fmt.Errorf("failed to render event: %w", err)
+			}
+			return nil
+		})
+
+		// flush rendered output before the summary
+		if cerr := r.Close(); err == nil || errors.Is(err, context.Canceled) {
+			err = cerr
 		}
+		for i, p := range patterns {
+			if len(patterns) > 1 {
+				fmt.Fprintf(os.Stderr, "%s: ", p)
+			}
+			fmt.Fprintln(os.Stderr, stats[i].Summary("events", &cursors[i]))
+		}
+
+		if err != nil {
+			return fmt.Errorf("event stream failed: %w", err)
+		}
+		return nil
 	},
 }
+
+func init() {
+	subscribeWhere = filter.WhereFlag(SubscribeCmd.Flags())
+	subscribePattern = pattern.EventFlags(SubscribeCmd.Flags())
+	SubscribeCmd.Flags().Bool("strict", false, "Fail on invalid messages instead of skipping them")
+	SubscribeCmd.Flags().String("abi", "", "Contract ABI file used to type event arguments, instead of the stored ABI")
+	SubscribeCmd.Flags().Int("max-reconnects", 0, "Consecutive failed reconnects before giving up, 0 retries forever")
+	SubscribeCmd.Flags().String("patterns-file", "", "File with additional patterns, one per line (- for stdin)")
+}
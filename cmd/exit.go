@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+)
+
+// Process exit codes, so scripts can tell failure causes apart
+const (
+	ExitOK           = 0
+	ExitError        = 1
+	ExitUnauthorized = 3
+	ExitNotFound     = 4
+	ExitRateLimited  = 5
+	ExitServerError  = 6
+	ExitNetwork      = 7
+	ExitBadRequest   = 8
+	ExitInterrupted  = 130
+	ExitTerminated   = 143
+)
+
+// exitCode maps an error returned by a command to a process exit code
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	if apiErr, ok := client.AsAPIError(err); ok {
+		switch {
+		case apiErr.IsUnauthorized():
+			return ExitUnauthorized
+		case apiErr.IsNotFound():
+			return ExitNotFound
+		case apiErr.IsRateLimited():
+			return ExitRateLimited
+		case apiErr.IsServerError():
+			return ExitServerError
+		default:
+			return ExitBadRequest
+		}
+	}
+
+	if errors.Is(err, config.ErrABINotFound) {
+		return ExitNotFound
+	}
+
+	// file errors also satisfy net.Error, so match the concrete types of
+	// failed requests and connections
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return ExitNetwork
+	}
+
+	return ExitError
+}
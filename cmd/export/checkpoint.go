@@ -0,0 +1,83 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Checkpoint records the progress of an export after each completed page
+type Checkpoint struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Format  string `json:"format"`
+	PerPage int    `json:"per_page"`
+	// Page is the next page to fetch
+	Page int `json:"page"`
+	// Offset is the size of the output file after the last completed page
+	Offset int64 `json:"offset"`
+	// Written is the number of records written so far
+	Written int `json:"written"`
+	Total   int `json:"total"`
+	// Position is the block or position of the oldest record written, nil
+	// before any record was written. Results are listed newest first, so
+	// records that moved onto later pages while exporting are newer and are
+	// skipped however far they moved.
+	Position *uint64 `json:"position,omitempty"`
+	// Keys identifies the records written at Position, which may be followed
+	// by more records at the same position
+	Keys []string `json:"keys"`
+}
+
+// loadCheckpoint reads the checkpoint at path, returning nil if it does not exist
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint %s: %w", path, err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// save writes the checkpoint atomically so an interrupted write never leaves
+// a corrupt checkpoint behind
+func (cp *Checkpoint) save(path string) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write checkpoint %s: %w", path, err)
+	}
+	return nil
+}
+
+// matches reports why cp cannot be used to resume the given export, if at all
+func (cp *Checkpoint) matches(kind, pattern, format string, perPage int) error {
+	switch {
+	case cp.Kind != kind:
+		return fmt.Errorf("checkpoint is for %s, not %s", cp.Kind, kind)
+	case cp.Pattern != pattern:
+		return fmt.Errorf("checkpoint is for pattern %s, not %s", cp.Pattern, pattern)
+	case cp.Format != format:
+		return fmt.Errorf("checkpoint is for %s output, not %s", cp.Format, format)
+	case cp.PerPage != perPage:
+		return fmt.Errorf("checkpoint was written with --perPage %d, not %d", cp.PerPage, perPage)
+	case cp.Written > 0 && cp.Position == nil:
+		return errors.New("checkpoint was written by an older version")
+	}
+	return nil
+}
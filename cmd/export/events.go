@@ -0,0 +1,56 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var eventsPattern *pattern.Flags
+
+var EventsCmd = &cobra.Command{
+	Use:   "events [pattern]",
+	Short: "Export contract events matching pattern",
+	Long: `Export collected contract events matching pattern
+	Arguments:
+	  pattern - search pattern chain.network.address.Event (eg. ethereum.mainnet.0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2.Transfer),
+	            or build it with --chain, --network, --address and --event
+
+Events are deduplicated by transaction hash and log index, or by contract, event
+and arguments when the API gives no log index. Invalid events are skipped with a
+warning.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, err := eventsPattern.Pattern(args)
+		if err != nil {
+			return err
+		}
+		return run(cmd.Context(), "events", pattern, fetchEvents)
+	},
+}
+
+func init() {
+	eventsPattern = pattern.EventFlags(EventsCmd.Flags())
+}
+
+func fetchEvents(ctx context.Context, c *client.Client, pattern string, opts client.ListOptions) ([]item, int, error) {
+	details, err := c.ListEvents(ctx, pattern, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]item, 0, len(details.Details))
+	for _, fields := range details.Details {
+		e, err := lib.NewEvent(fields)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s\n", err)
+			continue
+		}
+		items = append(items, item{record: e.Record(), position: e.BlockNumber, key: e.Key()})
+	}
+	return items, details.Meta.Total, nil
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// item is a rendered record together with its block or position and the key
+// used for deduplication
+type item struct {
+	record   format.Record
+	position uint64
+	key      string
+}
+
+// fetchFunc fetches one page of records for pattern
+type fetchFunc func(ctx context.Context, c *client.Client, pattern string, opts client.ListOptions) ([]item, int, error)
+
+// outputFormat picks the export format from the file extension, falling back
+// to --output
+func outputFormat(path string) (string, error) {
+	name := config.Config.Output
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		name = "csv"
+	case ".tsv":
+		name = "tsv"
+	case ".ndjson", ".jsonl":
+		name = "ndjson"
+	}
+
+	switch name {
+	case "csv", "tsv", "ndjson":
+		return name, nil
+	}
+	return "", fmt.Errorf("export supports csv, tsv and ndjson output, not %s", name)
+}
+
+// run exports every page of kind records matching pattern to the --out file
+func run(ctx context.Context, kind, pattern string, fetch fetchFunc) error {
+	name, err := outputFormat(out)
+	if err != nil {
+		return err
+	}
+
+	cpPath := checkpointPath
+	if cpPath == "" {
+		cpPath = out + ".checkpoint"
+	}
+
+	cp, err := loadCheckpoint(cpPath)
+	if err != nil {
+		return err
+	}
+
+	var f *os.File
+	if cp != nil {
+		if err := cp.matches(kind, pattern, name, perPage); err != nil {
+			return fmt.Errorf("cannot resume from %s: %w (remove it to start over)", cpPath, err)
+		}
+		if f, err = os.OpenFile(out, os.O_RDWR, 0o644); err != nil {
+			return fmt.Errorf("open %s for resume: %w", out, err)
+		}
+		// drop anything written after the last completed page
+		if err := f.Truncate(cp.Offset); err != nil {
+			f.Close()
+			return fmt.Errorf("truncate %s: %w", out, err)
+		}
+		if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+			f.Close()
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "resuming %s export at page %d (%d records written)\n", kind, cp.Page, cp.Written)
+	} else {
+		cp = &Checkpoint{Kind: kind, Pattern: pattern, Format: name, PerPage: perPage}
+		if f, err = os.Create(out); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	r, err := format.New(name, f, format.Options{Stream: true, NoHeader: cp.Offset > 0})
+	if err != nil {
+		return err
+	}
+
+	// records written at the oldest position so far, see Checkpoint.Position
+	atPosition := make(map[string]bool, len(cp.Keys))
+	for _, k := range cp.Keys {
+		atPosition[k] = true
+	}
+
+	c, err := config.NewClient()
+	if err != nil {
+		return err
+	}
+	opts := client.PageOptions{
+		StartPage:   cp.Page,
+		PerPage:     perPage,
+		All:         true,
+		Concurrency: concurrency,
+		AfterPage: func(page, total int) error {
+			// the renderer streams, so every record is already in f
+			if err := f.Sync(); err != nil {
+				return err
+			}
+			offset, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+
+			cp.Page = page + 1
+			cp.Offset = offset
+			cp.Total = total
+			cp.Keys = make([]string, 0, len(atPosition))
+			for k := range atPosition {
+				cp.Keys = append(cp.Keys, k)
+			}
+			sort.Strings(cp.Keys)
+			return cp.save(cpPath)
+		},
+	}
+
+	err = client.Paginate(ctx, opts,
+		func(ctx context.Context, page int) ([]item, int, error) {
+			return fetch(ctx, c, pattern, client.ListOptions{Page: page, PerPage: perPage})
+		},
+		func(it item) error {
+			if cp.Position != nil {
+				// already written before the results shifted
+				if it.position > *cp.Position || it.position == *cp.Position && atPosition[it.key] {
+					return nil
+				}
+			}
+			if cp.Position == nil || it.position < *cp.Position {
+				position := it.position
+				cp.Position = &position
+				atPosition = map[string]bool{}
+			}
+			atPosition[it.key] = true
+			cp.Written++
+			return r.Write(it.record)
+		})
+	if err != nil {
+		return fmt.Errorf("export stopped at page %d, run again to resume: %w", cp.Page, err)
+	}
+
+	if err := os.Remove(cpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d of %d %s to %s\n", cp.Written, cp.Total, kind, out)
+	return nil
+}
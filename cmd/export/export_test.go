@@ -0,0 +1,232 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+)
+
+// source serves items newest first like the list endpoints, failing the
+// page in failAt once
+type source struct {
+	mu     sync.Mutex
+	items  []item
+	failAt int
+	fails  int
+}
+
+func (s *source) fetch(ctx context.Context, c *client.Client, pattern string, opts client.ListOptions) ([]item, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if opts.Page == s.failAt && s.fails == 0 {
+		s.fails++
+		return nil, 0, errors.New("connection reset")
+	}
+	start := opts.Page * opts.PerPage
+	if start >= len(s.items) {
+		return nil, len(s.items), nil
+	}
+	end := min(start+opts.PerPage, len(s.items))
+	return s.items[start:end], len(s.items), nil
+}
+
+func newItem(id string, position uint64) item {
+	return item{
+		record:   format.Record{{Name: "id", Value: id}, {Name: "position", Value: position}},
+		position: position,
+		key:      id,
+	}
+}
+
+// original returns n items, two at each position, newest first
+func original(n int) []item {
+	items := make([]item, n)
+	for i := range items {
+		items[i] = newItem(fmt.Sprintf("r%02d", i), uint64(100-i/2))
+	}
+	return items
+}
+
+// setup points the export flags at a csv file in a temporary directory
+func setup(t *testing.T) string {
+	t.Helper()
+	config.Config.APIKey = "test"
+	out = filepath.Join(t.TempDir(), "out.csv")
+	checkpointPath = ""
+	perPage = 5
+	concurrency = 1
+	return out
+}
+
+func readRows(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func wantRows(t *testing.T, path string, items []item) {
+	t.Helper()
+	want := []string{"id,position"}
+	for _, it := range items {
+		want = append(want, fmt.Sprintf("%s,%d", it.key, it.position))
+	}
+	if got := readRows(t, path); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("exported rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExport(t *testing.T) {
+	path := setup(t)
+	src := &source{items: original(12), failAt: -1}
+	if err := run(context.Background(), "events", "p", src.fetch); err != nil {
+		t.Fatal(err)
+	}
+	wantRows(t, path, src.items)
+	if _, err := os.Stat(path + ".checkpoint"); !os.IsNotExist(err) {
+		t.Errorf("checkpoint left behind after a complete export: %v", err)
+	}
+}
+
+func TestExportResumesAfterFailure(t *testing.T) {
+	for _, c := range []int{1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", c), func(t *testing.T) {
+			path := setup(t)
+			concurrency = c
+			items := original(25)
+			src := &source{items: items, failAt: 3}
+
+			err := run(context.Background(), "events", "p", src.fetch)
+			if err == nil || !strings.Contains(err.Error(), "run again to resume") {
+				t.Fatalf("run = %v, want the fetch failure", err)
+			}
+
+			cp, err := loadCheckpoint(path + ".checkpoint")
+			if err != nil || cp == nil {
+				t.Fatalf("checkpoint = %v, %v", cp, err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cp.Page != 3 || cp.Written != 15 || cp.Offset != info.Size() || *cp.Position != 93 {
+				t.Errorf("checkpoint = %+v, want page 3, 15 written at offset %d, position 93", cp, info.Size())
+			}
+
+			// a write interrupted halfway through a page
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString("r15,9")
+			f.Close()
+
+			// new records arrived, shifting everything onto later pages
+			src.mu.Lock()
+			src.items = append([]item{newItem("n0", 102), newItem("n1", 101), newItem("n2", 100)}, items...)
+			src.mu.Unlock()
+			if err := run(context.Background(), "events", "p", src.fetch); err != nil {
+				t.Fatal(err)
+			}
+			wantRows(t, path, items)
+		})
+	}
+}
+
+func TestExportBoundaryDedupeByKey(t *testing.T) {
+	path := setup(t)
+	// six records of block 8, the first page ends in the middle of them
+	items := []item{newItem("a", 9), newItem("b", 8), newItem("c", 8), newItem("d", 8), newItem("e", 8),
+		newItem("f", 8), newItem("g", 8), newItem("h", 7)}
+	src := &source{items: items, failAt: 1}
+	if err := run(context.Background(), "events", "p", src.fetch); err == nil {
+		t.Fatal("run succeeded, want the fetch failure")
+	}
+
+	// two new records shift c..e back onto the second page: they were
+	// written at block 8 and are skipped, f and g at the same block are not
+	src.mu.Lock()
+	src.items = append([]item{newItem("new1", 10), newItem("new2", 10)}, items...)
+	src.mu.Unlock()
+	if err := run(context.Background(), "events", "p", src.fetch); err != nil {
+		t.Fatal(err)
+	}
+	wantRows(t, path, items)
+}
+
+func TestExportRejectsMismatchedCheckpoint(t *testing.T) {
+	path := setup(t)
+	src := &source{items: original(12), failAt: 1}
+	if err := run(context.Background(), "events", "p", src.fetch); err == nil {
+		t.Fatal("run succeeded, want the fetch failure")
+	}
+
+	tests := []struct {
+		kind, pattern string
+		perPage       int
+		want          string
+	}{
+		{"transfers", "p", 5, "checkpoint is for events"},
+		{"events", "q", 5, "checkpoint is for pattern p"},
+		{"events", "p", 10, "--perPage 5"},
+	}
+	for _, tt := range tests {
+		perPage = tt.perPage
+		err := run(context.Background(), tt.kind, tt.pattern, src.fetch)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), "remove it to start over") {
+			t.Errorf("run %s %s: err = %v, want %q", tt.kind, tt.pattern, err, tt.want)
+		}
+	}
+
+	if got := readRows(t, path); len(got) != 6 {
+		t.Errorf("rejected resume changed the output: %v", got)
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp")
+	if cp, err := loadCheckpoint(path); cp != nil || err != nil {
+		t.Fatalf("loadCheckpoint of a missing file = %v, %v", cp, err)
+	}
+
+	position := uint64(42)
+	cp := &Checkpoint{Kind: "events", Pattern: "p", Format: "csv", PerPage: 5, Page: 2, Offset: 99, Written: 10, Total: 50, Position: &position, Keys: []string{"a", "b"}}
+	if err := cp.save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary checkpoint left behind: %v", err)
+	}
+
+	loaded, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Page != 2 || loaded.Offset != 99 || *loaded.Position != 42 || strings.Join(loaded.Keys, ",") != "a,b" {
+		t.Errorf("loaded %+v, want %+v", loaded, cp)
+	}
+	if err := loaded.matches("events", "p", "csv", 5); err != nil {
+		t.Errorf("matches = %v", err)
+	}
+
+	loaded.Position = nil
+	if err := loaded.matches("events", "p", "csv", 5); err == nil {
+		t.Error("checkpoint without position accepted")
+	}
+
+	os.WriteFile(path, []byte("{"), 0o644)
+	if _, err := loadCheckpoint(path); err == nil {
+		t.Error("corrupt checkpoint loaded")
+	}
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	out            string
+	checkpointPath string
+	perPage        int
+	concurrency    int
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all pages of a list to a file, resumable after failures",
+	Long: `Export every page of transfers or events matching a pattern to a file.
+
+Progress is recorded in a checkpoint file after each completed page. When an
+export is interrupted, running the same command again resumes from the
+checkpoint: partially written pages are truncated and records already written
+are skipped, so each record appears exactly once. Results are listed newest
+first, so records newer than the oldest one written, which moved onto later
+pages as new records arrived, are skipped by their block or position however
+many new records arrived. The checkpoint is removed when the export completes.
+
+The output format is taken from the file extension (.csv, .tsv, .ndjson,
+.jsonl) or from --output, and must be one of csv, tsv or ndjson.`,
+}
+
+func init() {
+	ExportCmd.PersistentFlags().StringVar(&out, "out", "", "File to write exported records to (required)")
+	ExportCmd.PersistentFlags().StringVar(&checkpointPath, "checkpoint", "", "Checkpoint file (default <out>.checkpoint)")
+	ExportCmd.PersistentFlags().IntVar(&perPage, "perPage", 100, "Records to fetch per page")
+	ExportCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 1, "Number of pages to fetch in parallel")
+	_ = ExportCmd.MarkPersistentFlagRequired("out")
+
+	ExportCmd.AddCommand(TransfersCmd)
+	ExportCmd.AddCommand(EventsCmd)
+}
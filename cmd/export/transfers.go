@@ -0,0 +1,47 @@
+package export
+
+import (
+	"context"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
+	"github.com/spf13/cobra"
+)
+
+var transfersPattern *pattern.Flags
+
+var TransfersCmd = &cobra.Command{
+	Use:   "transfers [pattern]",
+	Short: "Export fungible token transfers matching pattern",
+	Long: `Export fungible token transfers matching pattern
+	Arguments:
+	  pattern - search pattern chain.network.token.from.to.size (eg. ethereum.mainnet.usdt.all.all.whale),
+	            or build it with --chain, --network, --token, --from, --to and --size
+
+Transfers are deduplicated by transaction hash, token, sender, recipient and
+amount.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, err := transfersPattern.Pattern(args)
+		if err != nil {
+			return err
+		}
+		return run(cmd.Context(), "transfers", pattern, fetchTransfers)
+	},
+}
+
+func init() {
+	transfersPattern = pattern.TransferFlags(TransfersCmd.Flags())
+}
+
+func fetchTransfers(ctx context.Context, c *client.Client, pattern string, opts client.ListOptions) ([]item, int, error) {
+	resp, err := c.ListTransfers(ctx, pattern, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]item, 0, len(resp.Transfers))
+	for _, t := range resp.Transfers {
+		items = append(items, item{record: t.Record(), position: uint64(t.Position), key: t.Key()})
+	}
+	return items, resp.Meta.Total, nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// filtersHelp is a help topic, shown by "heimdahl help filters"
+var filtersHelp = &cobra.Command{
+	Use:   "filters",
+	Short: "Syntax of --where filter expressions",
+	Long: `--where keeps only records matching an expression. It is evaluated on the
+client, so it works with every list and subscribe command and any output format.
+
+Fields are the names shown with -o json (eg. amount, from, to, symbol, block).
+Dotted names reach into nested values such as decoded event arguments
+(args.wad); argument names may also be used on their own. A name which is not
+a field of the record is an error. Use args.<name> for arguments that not
+every event has, it is empty for the others.
+
+Operators:
+  == != < <= > >=     compare numbers, times and strings (case insensitive)
+  =~ !~               match a regular expression
+  in, not in          membership of a [list] or of an @file with one value per line
+  and, or, not, ( )   combine conditions (&&, || and ! also work)
+
+Amounts compare as decimals using the token's decimals. Numbers may use _
+separators and the k, m and b suffixes. Strings must be quoted ('usdc'), hex
+values such as addresses need no quotes; times may be compared with RFC3339
+strings or relative times.
+Repeating --where combines the expressions with and.
+
+Examples:
+  heimdahl transfer list ethereum.mainnet.usdt.all.all.all --all --where 'amount > 1m and to in @watchlist.txt'
+  heimdahl transfer subscribe ethereum.mainnet.usdc.all.all.all --where 'from == 0x28c6c06298d514db089934071355e5743bf21d60'
+  heimdahl event list ethereum.mainnet.0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2.Transfer --where 'wad >= 10e18 and time > "24h"'
+`,
+}
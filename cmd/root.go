@@ -2,15 +2,23 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/cmd/abi"
+	"github.com/heimdahl-xyz/heimdahl-cli/cmd/auth"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/chain"
+	"github.com/heimdahl-xyz/heimdahl-cli/cmd/configure"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/contract"
+	"github.com/heimdahl-xyz/heimdahl-cli/cmd/decode"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/event"
+	"github.com/heimdahl-xyz/heimdahl-cli/cmd/export"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/subscription"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/swap"
 	"github.com/heimdahl-xyz/heimdahl-cli/cmd/transfer"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 )
 
 var RootCmd = &cobra.Command{
@@ -27,13 +35,43 @@ Examples:
   heimdahl contract show 0x060...6d              # View contract details
 
 Built for developers who need reliable blockchain data without infrastructure overhead.
+
+Exit codes:
+  0  success
+  1  general error
+  3  API key missing, invalid or not authorized
+  4  requested resource not found
+  5  rate limited
+  6  server error
+  7  network error or timeout
+  8  request rejected by server
+  130  interrupted (Ctrl+C)
+  143  terminated (SIGTERM)
 `,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// managing profiles must keep working with a broken or missing profile
+		if cmd.HasParent() && cmd.Parent() == configure.ConfigCmd {
+			return nil
+		}
+		return config.ApplyProfile(cmd.Flags())
+	},
 }
 
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	ctx, interrupted := signalContext()
+	err := RootCmd.ExecuteContext(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+
+	// commands stop cleanly on a signal, but scripts still need to know
+	if sig := interrupted(); sig != nil {
+		os.Exit(signalExitCode(sig))
+	}
+	if err != nil {
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -41,7 +79,21 @@ func init() {
 	// Add a global flag for host
 	RootCmd.PersistentFlags().StringVarP(&config.Config.APIURL, "host", "H", "api.heimdahl.xyz", "Host URL for the API server")
 	RootCmd.PersistentFlags().BoolVar(&config.Config.Secure, "secure", true, "Use secure connection to server")
-	RootCmd.PersistentFlags().StringVarP(&config.Config.APIKey, "apiKey", "K", "demo", "API NetworkKey for connection to server")
+	RootCmd.PersistentFlags().StringVarP(&config.Config.APIKey, "apiKey", "K", "", "API key for connection to server (prefer \"heimdahl auth login\" or HEIMDAHL_API_KEY)")
+	RootCmd.PersistentFlags().StringVarP(&config.Config.Output, "output", "o", "table", "Output format ("+strings.Join(format.Names(), ",")+")")
+	RootCmd.PersistentFlags().StringVar(&config.Config.Output, "format", "table", "Output format")
+	_ = RootCmd.PersistentFlags().MarkDeprecated("format", "use --output instead")
+	RootCmd.PersistentFlags().StringSliceVar(&config.Config.Fields, "fields", nil, "Comma separated fields to output, in order (eg. from,to,amount,symbol)")
+	RootCmd.PersistentFlags().StringVar(&config.Config.Sort, "sort", "", "Sort list results by fields, eg. amount:desc,timestamp")
+	RootCmd.PersistentFlags().BoolVar(&config.Config.NoHeader, "no-header", false, "Omit the header row of table, markdown, csv and tsv output")
+	RootCmd.PersistentFlags().BoolVar(&config.Config.WSHeaderAuth, "ws-header-auth", false, "Send the API key as a header on websocket handshakes instead of in the URL")
+	RootCmd.PersistentFlags().StringVar(&config.Config.Profile, "profile", "", "Configuration profile to use (env HEIMDAHL_PROFILE)")
+
+	// Retry policy applied to all REST calls
+	RootCmd.PersistentFlags().IntVar(&config.Config.RetryMaxAttempts, "retry-max-attempts", client.DefaultRetryPolicy.MaxAttempts, "Maximum attempts per request, 1 disables retries")
+	RootCmd.PersistentFlags().DurationVar(&config.Config.RetryBackoff, "retry-backoff", client.DefaultRetryPolicy.InitialBackoff, "Initial delay between retries, doubled on every attempt")
+	RootCmd.PersistentFlags().DurationVar(&config.Config.RetryMaxBackoff, "retry-max-backoff", client.DefaultRetryPolicy.MaxBackoff, "Maximum delay between retries")
+	RootCmd.PersistentFlags().Float64Var(&config.Config.RetryJitter, "retry-jitter", client.DefaultRetryPolicy.Jitter, "Random fraction (0-1) applied to retry delays")
 
 	RootCmd.AddCommand(contract.ContractCmd)
 	RootCmd.AddCommand(chain.ChainCmd)
@@ -49,4 +101,10 @@ func init() {
 	RootCmd.AddCommand(transfer.TransferCmd)
 	RootCmd.AddCommand(swap.SwapCmd)
 	RootCmd.AddCommand(subscription.SubscriptionCmd)
+	RootCmd.AddCommand(configure.ConfigCmd)
+	RootCmd.AddCommand(auth.AuthCmd)
+	RootCmd.AddCommand(export.ExportCmd)
+	RootCmd.AddCommand(abi.AbiCmd)
+	RootCmd.AddCommand(decode.DecodeCmd)
+	RootCmd.AddCommand(filtersHelp)
 }
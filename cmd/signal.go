@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// signalContext returns a context cancelled on the first SIGINT or SIGTERM,
+// letting commands close connections and flush output before exiting. A
+// second signal exits immediately. The returned func reports the signal
+// received, if any.
+func signalContext() (context.Context, func() os.Signal) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	var received os.Signal
+
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-ch
+		mu.Lock()
+		received = sig
+		mu.Unlock()
+		cancel()
+
+		sig = <-ch
+		fmt.Fprintln(os.Stderr, "forced exit")
+		os.Exit(signalExitCode(sig))
+	}()
+
+	return ctx, func() os.Signal {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+// signalExitCode follows the shell convention of 128 + signal number
+func signalExitCode(sig os.Signal) int {
+	if sig == syscall.SIGTERM {
+		return ExitTerminated
+	}
+	return ExitInterrupted
+}
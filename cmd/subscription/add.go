@@ -2,6 +2,7 @@ package subscription
 
 import (
 	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	"log"
@@ -41,14 +42,29 @@ type AddTransferSubscriptionParams struct {
 	SizeBucket string
 }
 
-func (t *TransferTopic) String() Topic {
-	return Topic(fmt.Sprintf("%s.%s.%s.%s.%s.%s",
-		t.Chain,
-		t.Network,
-		t.Token,
-		t.From,
-		t.To,
-		t.Size))
+// Pattern returns the validated transfer pattern of the topic, with empty
+// segments turned into wildcards
+func (t *TransferTopic) Pattern() (pattern.Transfer, error) {
+	p := pattern.Transfer{
+		Chain:   t.Chain,
+		Network: t.Network,
+		Token:   t.Token,
+		From:    t.From,
+		To:      t.To,
+		Size:    t.Size,
+	}
+	err := p.Validate()
+	return p, err
+}
+
+// Topic returns the stream topic of the transfer pattern, failing when the
+// pattern is invalid
+func (t *TransferTopic) Topic() (Topic, error) {
+	p, err := t.Pattern()
+	if err != nil {
+		return "", err
+	}
+	return Topic(p.String()), nil
 }
 
 // ValidateURL checks if the URL contains a valid scheme and either an IP address or hostname
@@ -212,6 +228,19 @@ var AddTransferCmd = &cobra.Command{
 			subscription.Wallet = wallet
 		}
 
+		// check the prompted chain, token and addresses before they are sent
+		topic := TransferTopic{
+			Chain:   subscription.Chain,
+			Network: subscription.Network,
+			Token:   subscription.Token,
+			From:    subscription.From,
+			To:      subscription.To,
+			Size:    subscription.SizeBucket,
+		}
+		if _, err := topic.Topic(); err != nil {
+			log.Fatalf("Invalid subscription: %v\n", err)
+		}
+
 		fmt.Printf("\nSubscription Created: %+v\n", subscription)
 
 		//contractAddress := args[0]
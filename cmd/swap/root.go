@@ -11,4 +11,5 @@ var SwapCmd = &cobra.Command{
 
 func init() {
 	SwapCmd.AddCommand(ListCmd)
+	SwapCmd.AddCommand(SubscribeCmd)
 }
@@ -0,0 +1,126 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/heimdahl-xyz/heimdahl-cli/config"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/filter"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
+	"github.com/spf13/cobra"
+	"net/url"
+	"os"
+)
+
+var maxReconnects int
+var patternsFile string
+var subscribePattern *pattern.Flags
+var subscribeWhere *filter.Flags
+
+// SubscribeCmd represents the subscribe command
+var SubscribeCmd = &cobra.Command{
+	Use:   "subscribe [pattern...]",
+	Short: "subscribe to realtime swaps for fungible tokens by pattern",
+	Long: `Subscribe to realtime token swaps
+	Arguments:
+	  pattern - search pattern chain.network.token1.token2.size (eg. ethereum.mainnet.usdt.weth.all)
+
+Several patterns may be given as arguments or in a file (--patterns-file, one per
+line). Each pattern gets its own connection and swaps are merged into one
+output in arrival order, tagged with the pattern that matched them.
+
+Without arguments the pattern is built from --chain, --network, --token1, --token2 and --size.
+Set on the command line, these flags also override segments of the patterns.
+
+--where keeps only messages matching an expression, see "heimdahl help filters".
+
+Connections are kept alive with pings and re-established with backoff when they
+drop. Swaps carry no stream position, so swaps made while disconnected are not
+replayed.`,
+	Args: cobra.ArbitraryArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patterns, err := pattern.FromArgs(args, patternsFile)
+		if err != nil {
+			return err
+		}
+		if patterns, err = subscribePattern.Patterns(patterns); err != nil {
+			return err
+		}
+
+		where, err := subscribeWhere.Expr()
+		if err != nil {
+			return err
+		}
+
+		r, err := config.NewStreamRenderer()
+		if err != nil {
+			return err
+		}
+
+		stats := make([]client.StreamStats, len(patterns))
+		streams := make([]client.StreamOptions, len(patterns))
+		for i, p := range patterns {
+			streams[i] = client.StreamOptions{
+				Path:          "/v1/swaps/stream/" + url.PathEscape(p),
+				Name:          p,
+				MaxReconnects: maxReconnects,
+				Stats:         &stats[i],
+			}
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		err = c.SubscribeAll(cmd.Context(), streams, func(i int, message []byte) error {
+			var swap lib.Swap
+			if err := json.Unmarshal(message, &swap); err != nil {
+				return fmt.Errorf("error unmarshalling message: %w", err)
+			}
+
+			rec := swap.Record()
+			if len(patterns) > 1 {
+				rec = append(format.Record{{Name: "pattern", Value: patterns[i]}}, rec...)
+			}
+			matched, err := where.Match(rec)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return nil
+			}
+			if err := r.Write(rec); err != nil {
+				return fmt.Errorf("failed to render swap: %w", err)
+			}
+			return nil
+		})
+
+		// flush rendered output before the summary
+		if cerr := r.Close(); err == nil || errors.Is(err, context.Canceled) {
+			err = cerr
+		}
+		for i, p := range patterns {
+			if len(patterns) > 1 {
+				fmt.Fprintf(os.Stderr, "%s: ", p)
+			}
+			fmt.Fprintln(os.Stderr, stats[i].Summary("swaps", nil))
+		}
+
+		if err != nil {
+			return fmt.Errorf("swap stream failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	subscribeWhere = filter.WhereFlag(SubscribeCmd.Flags())
+	subscribePattern = pattern.SwapFlags(SubscribeCmd.Flags())
+	SubscribeCmd.Flags().IntVar(&maxReconnects, "max-reconnects", 0, "Consecutive failed reconnects before giving up, 0 retries forever")
+	SubscribeCmd.Flags().StringVar(&patternsFile, "patterns-file", "", "File with additional patterns, one per line (- for stdin)")
+}
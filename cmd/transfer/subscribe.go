@@ -1,162 +1,142 @@
 package transfer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/gorilla/websocket"
 	"github.com/heimdahl-xyz/heimdahl-cli/config"
-	format2 "github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
 	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/filter"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/pattern"
 	"github.com/spf13/cobra"
 	"log"
-	"net/http"
+	"net/url"
 	"os"
-	"os/signal"
-	"strings"
-	"syscall"
+	"strconv"
 )
 
-// PrintTransfer prints a single transfer in a human-readable format
-func PrintTransfer(transfer *lib.FungibleTokenTransfer) {
-	// Create a horizontal line with a longer length to accommodate full addresses
-	horizLine := strings.Repeat("─", 120)
-
-	fmt.Println("┌" + horizLine + "┐")
-
-	// Format: Chain.Network → Symbol Transfer
-	chainStr := transfer.Chain
-	if transfer.Network != "" && transfer.Network != "mainnet" {
-		chainStr += "." + transfer.Network
-	}
-	fmt.Printf("│ \033[1m%s → %s Transfer\033[0m\n", chainStr, transfer.Symbol)
-
-	// Time and Transaction
-	fmt.Printf("│ \033[90mTimestamp:\033[0m %s\n", formatTimestamp(transfer.Timestamp))
-	fmt.Printf("│ \033[90mTX Hash:  \033[0m %s\n", transfer.TxHash)
-
-	// Add a separator
-	fmt.Println("│ " + strings.Repeat("─", 118))
-
-	// From → To with owners if available
-	fmt.Printf("│ \033[90mFrom:     \033[0m %s", transfer.FromAddress)
-	if transfer.FromOwner != "" && transfer.FromOwner != transfer.FromAddress {
-		fmt.Printf(" \033[90m(Owner: %s)\033[0m", transfer.FromOwner)
-	}
-	fmt.Println()
-
-	fmt.Printf("│ \033[90mTo:       \033[0m %s", transfer.ToAddress)
-	if transfer.ToOwner != "" && transfer.ToOwner != transfer.ToAddress {
-		fmt.Printf(" \033[90m(Owner: %s)\033[0m", transfer.ToOwner)
-	}
-	fmt.Println()
-
-	// Amount with symbol
-	formattedAmount := format2.FormatAmountBigInt(transfer.Amount, transfer.Decimals)
-	fmt.Printf("│ \033[90mAmount:   \033[0m \033[1m%s %s\033[0m\n", formattedAmount, transfer.Symbol)
-
-	// Token address
-	fmt.Printf("│ \033[90mToken:    \033[0m %s\n", transfer.TokenAddress)
-
-	// Position
-	fmt.Printf("│ \033[90mPosition: \033[0m %d\n", transfer.Position)
-
-	fmt.Println("└" + horizLine + "┘")
-}
-
-// PrintTransfers prints multiple transfers with a header
-func PrintTransfers(transfers []*lib.FungibleTokenTransfer) {
-	if len(transfers) == 0 {
-		fmt.Println("No transfers found.")
-		return
-	}
-
-	fmt.Printf("Found %d transfers:\n\n", len(transfers))
-
-	for i, transfer := range transfers {
-		PrintTransfer(transfer)
-
-		// Add a newline between transfers, but not after the last one
-		if i < len(transfers)-1 {
-			fmt.Println()
-		}
-	}
-}
+var maxReconnects int
+var patternsFile string
+var subscribePattern *pattern.Flags
+var subscribeWhere *filter.Flags
 
 // SubscribeCmd represents the listen command
 var SubscribeCmd = &cobra.Command{
-	Use:   "subscribe [pattern]",
+	Use:   "subscribe [pattern...]",
 	Short: "subscribe to realtime transfer for fungibe tokens by pattern",
 	Long: `Subscribe to realtime events for contract 
 	Arguments:
-	  pattern - search pattern (required) (eg. ethereum.mainnet.0x1234.0x5677.whale)`,
-	Args: cobra.ExactArgs(1),
+	  pattern - search pattern chain.network.token.from.to.size (eg. ethereum.mainnet.usdt.all.all.whale)
 
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
-			cmd.Help()
-			return
-		}
+Several patterns may be given as arguments or in a file (--patterns-file, one per
+line). Each pattern gets its own connection and transfers are merged into one
+output in arrival order, tagged with the pattern that matched them.
 
-		pattern := args[0]
+Without arguments the pattern is built from --chain, --network, --token, --from, --to and --size.
+Set on the command line, these flags also override segments of the patterns.
 
-		// Prepare the WebSocket URL
-		wsURL := fmt.Sprintf("%s/v1/transfers/stream/%s?api_key=%s", config.GetWsHost(), pattern, config.GetApiKey())
+--where keeps only messages matching an expression, see "heimdahl help filters".
 
-		//log.Println(wsURL)
-		headers := make(http.Header)
+Connections are kept alive with pings and re-established with backoff when they
+drop, resuming from the last received position without duplicates.`,
+	Args: cobra.ArbitraryArgs,
 
-		headers.Set("Content-Type", "application/json")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patterns, err := pattern.FromArgs(args, patternsFile)
+		if err != nil {
+			return err
+		}
+		if patterns, err = subscribePattern.Patterns(patterns); err != nil {
+			return err
+		}
 
-		signalChannel := make(chan os.Signal, 1)
-		// Notify when SIGINT (Ctrl+C) or SIGTERM signal is received
-		signal.Notify(signalChannel, syscall.SIGINT, syscall.SIGTERM)
+		where, err := subscribeWhere.Expr()
+		if err != nil {
+			return err
+		}
 
-		conn, _, err := websocket.DefaultDialer.Dial(wsURL, headers)
+		r, err := config.NewStreamRenderer()
 		if err != nil {
-			log.Fatal("Error connecting to WebSocket:", err)
+			return err
 		}
-		defer conn.Close()
-
-		// separate goroutine to listen for signals
-		go func() {
-			<-signalChannel
-			os.Exit(0)
-		}()
-
-		// Format and print the struct fields as a table row
-
-		fmt.Printf("| %-15s | %-20s | %-20s | %-20s | %-20s | %-15s | %-15s | %-10s | %-10s | %-25s | %-10s | %-10s | %-10s |\n",
-			"Timestamp",
-			"From Address",
-			"From Owner",
-			"To Address",
-			"To Owner",
-			"Amount",
-			"Token Address",
-			"Symbol",
-			"Chain",
-			"Network",
-			"Tx Hash",
-			"Decimals",
-			"Position")
-
-		// Listen for messages
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Println("Error reading message:", err)
-				return
+
+		// each connection keeps its own cursor, which skips transfers
+		// replayed after resuming from the last position
+		cursors := make([]client.Cursor, len(patterns))
+		stats := make([]client.StreamStats, len(patterns))
+		streams := make([]client.StreamOptions, len(patterns))
+		for i, p := range patterns {
+			cursor := &cursors[i]
+			streams[i] = client.StreamOptions{
+				Path:          "/v1/transfers/stream/" + url.PathEscape(p),
+				Name:          p,
+				MaxReconnects: maxReconnects,
+				Stats:         &stats[i],
+				Resume: func() url.Values {
+					pos, ok := cursor.Resume()
+					if !ok {
+						return nil
+					}
+					return url.Values{"from_position": {strconv.FormatUint(pos, 10)}}
+				},
 			}
-			//log.Println(string(message))
+		}
+
+		c, err := config.NewClient()
+		if err != nil {
+			return err
+		}
+		err = c.SubscribeAll(cmd.Context(), streams, func(i int, message []byte) error {
 			var transfer lib.FungibleTokenTransfer
-			err = json.Unmarshal(message, &transfer)
-			if err != nil {
+			if err := json.Unmarshal(message, &transfer); err != nil {
 				log.Printf("raw message %s", message)
-				log.Println("Error unmarshalling message:", err)
-				return
+				return fmt.Errorf("error unmarshalling message: %w", err)
+			}
+			if !cursors[i].Next(transfer.Position, transfer.Key()) {
+				return nil
+			}
+
+			rec := transfer.Record()
+			if len(patterns) > 1 {
+				rec = append(format.Record{{Name: "pattern", Value: patterns[i]}}, rec...)
 			}
-			PrintTransfer(&transfer)
+			matched, err := where.Match(rec)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return nil
+			}
+			if err := r.Write(rec); err != nil {
+				return fmt.Errorf("failed to render transfer: %w", err)
+			}
+			return nil
+		})
+
+		// flush rendered output before the summary
+		if cerr := r.Close(); err == nil || errors.Is(err, context.Canceled) {
+			err = cerr
+		}
+		for i, p := range patterns {
+			if len(patterns) > 1 {
+				fmt.Fprintf(os.Stderr, "%s: ", p)
+			}
+			fmt.Fprintln(os.Stderr, stats[i].Summary("transfers", &cursors[i]))
 		}
 
+		if err != nil {
+			return fmt.Errorf("transfer stream failed: %w", err)
+		}
+		return nil
 	},
 }
+
+func init() {
+	subscribeWhere = filter.WhereFlag(SubscribeCmd.Flags())
+	subscribePattern = pattern.TransferFlags(SubscribeCmd.Flags())
+	SubscribeCmd.Flags().IntVar(&maxReconnects, "max-reconnects", 0, "Consecutive failed reconnects before giving up, 0 retries forever")
+	SubscribeCmd.Flags().StringVar(&patternsFile, "patterns-file", "", "File with additional patterns, one per line (- for stdin)")
+}
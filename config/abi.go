@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoredABI is a contract ABI kept in the local ABI store
+type StoredABI struct {
+	Chain    string          `json:"chain"`
+	Address  string          `json:"address"`
+	Name     string          `json:"name,omitempty"`
+	Imported time.Time       `json:"imported"`
+	ABI      json.RawMessage `json:"abi"`
+}
+
+// ErrABINotFound is returned when no ABI is stored for a contract
+var ErrABINotFound = errors.New("ABI not found")
+
+var (
+	chainKeyRe   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	addressKeyRe = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// ABIDir returns the directory of the local ABI store
+func ABIDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "abi"), nil
+}
+
+// ABIKey normalises the chain and address an ABI is stored under. EVM
+// addresses are case insensitive, other chains' addresses are kept as given.
+func ABIKey(chain, address string) (string, string, error) {
+	chain = strings.ToLower(strings.TrimSpace(chain))
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		address = strings.ToLower(address)
+	}
+	if !chainKeyRe.MatchString(chain) {
+		return "", "", fmt.Errorf("invalid chain %q", chain)
+	}
+	if !addressKeyRe.MatchString(address) {
+		return "", "", fmt.Errorf("invalid address %q", address)
+	}
+	return chain, address, nil
+}
+
+func abiPath(chain, address string) (string, error) {
+	chain, address, err := ABIKey(chain, address)
+	if err != nil {
+		return "", err
+	}
+	dir, err := ABIDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, chain, address+".json"), nil
+}
+
+// SaveABI stores an ABI, replacing any ABI stored for the same contract
+func SaveABI(a *StoredABI) error {
+	chain, address, err := ABIKey(a.Chain, a.Address)
+	if err != nil {
+		return err
+	}
+	a.Chain, a.Address = chain, address
+	if a.Imported.IsZero() {
+		a.Imported = time.Now().UTC()
+	}
+
+	path, err := abiPath(chain, address)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create ABI store: %w", err)
+	}
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode ABI: %w", err)
+	}
+
+	// write to a temporary file first so a failed write never leaves a
+	// truncated ABI behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write ABI %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write ABI %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadABI returns the ABI stored for a contract, ErrABINotFound when there
+// is none
+func LoadABI(chain, address string) (*StoredABI, error) {
+	path, err := abiPath(chain, address)
+	if err != nil {
+		return nil, err
+	}
+	return readABI(path, chain, address)
+}
+
+func readABI(path, chain, address string) (*StoredABI, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no ABI stored for %s %s: %w", chain, address, ErrABINotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read ABI %s: %w", path, err)
+	}
+	var a StoredABI
+	if err := json.Unmarshal(b, &a); err != nil {
+		return nil, fmt.Errorf("parse ABI %s: %w", path, err)
+	}
+	return &a, nil
+}
+
+// ListABIs returns the stored ABIs ordered by chain and address, only those
+// of chain when it is not empty
+func ListABIs(chain string) ([]*StoredABI, error) {
+	dir, err := ABIDir()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := filepath.Join(dir, "*", "*.json")
+	if chain != "" {
+		pattern = filepath.Join(dir, strings.ToLower(chain), "*.json")
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	abis := make([]*StoredABI, 0, len(paths))
+	for _, path := range paths {
+		address := strings.TrimSuffix(filepath.Base(path), ".json")
+		a, err := readABI(path, filepath.Base(filepath.Dir(path)), address)
+		if err != nil {
+			return nil, err
+		}
+		abis = append(abis, a)
+	}
+	return abis, nil
+}
+
+// RemoveABI deletes the ABI stored for a contract
+func RemoveABI(chain, address string) error {
+	path, err := abiPath(chain, address)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no ABI stored for %s %s: %w", chain, address, ErrABINotFound)
+	}
+	return err
+}
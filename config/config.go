@@ -1,14 +1,37 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/client"
 )
 
 var Config struct {
 	APIURL string
 	APIKey string
 	Secure bool
+	// Profile selects a named profile from the config file
+	Profile string
+	// WSHeaderAuth sends the API key as a header on websocket handshakes
+	WSHeaderAuth bool
+	// Output is the output format used by all commands
+	Output string
+	// Fields selects and orders the output columns
+	Fields []string
+	// Sort orders list results, eg. "amount:desc,timestamp"
+	Sort string
+	// NoHeader omits header rows from tabular outputs
+	NoHeader bool
+
+	RetryMaxAttempts int
+	RetryBackoff     time.Duration
+	RetryMaxBackoff  time.Duration
+	RetryJitter      float64
 }
 
 func GetHost() string {
@@ -27,14 +50,124 @@ func GetWsHost() string {
 	}
 }
 
-func GetApiKey() string {
-	apk := os.Getenv("HEIMDAHL_API_KEY")
-	if apk == "" {
-		apk = Config.APIKey
+// DemoAPIKey is used when no API key has been configured
+const DemoAPIKey = "demo"
+
+// API key sources reported by ResolveApiKey
+const (
+	KeySourceFlag        = "--apiKey flag"
+	KeySourceEnv         = "HEIMDAHL_API_KEY environment variable"
+	KeySourceCredentials = "credentials store"
+	KeySourceDemo        = "demo key"
+)
+
+var resolvedKey, resolvedSource string
+
+// GetApiKey returns the API key, see ResolveApiKey
+func GetApiKey() (string, error) {
+	apk, _, err := ResolveApiKey()
+	if err != nil {
+		return "", fmt.Errorf("API key could not be loaded: %w", err)
+	}
+	return apk, nil
+}
+
+// ResolveApiKey returns the API key and where it was found. The --apiKey
+// flag takes precedence, followed by HEIMDAHL_API_KEY and the key stored with
+// "heimdahl auth login" for the active profile.
+func ResolveApiKey() (string, string, error) {
+	if resolvedKey != "" {
+		return resolvedKey, resolvedSource, nil
+	}
+
+	key, source, err := resolveApiKey()
+	if err != nil {
+		return "", "", err
+	}
+	resolvedKey, resolvedSource = key, source
+	return key, source, nil
+}
+
+func resolveApiKey() (string, string, error) {
+	if Config.APIKey != "" {
+		return Config.APIKey, KeySourceFlag, nil
+	}
+	if apk := os.Getenv("HEIMDAHL_API_KEY"); apk != "" {
+		return apk, KeySourceEnv, nil
+	}
+
+	creds, err := LoadCredentials()
+	if err != nil {
+		return "", "", err
 	}
-	if apk == "" {
-		log.Fatalf("API NetworkKey not found. Please set the HEIMDAHL_API_KEY environment variable or use the --apiKey flag")
+	if apk := creds.Keys[ProfileName()]; apk != "" {
+		return apk, KeySourceCredentials, nil
 	}
 
-	return apk
+	return DemoAPIKey, KeySourceDemo, nil
+}
+
+// MaskKey hides all but the last four characters of an API key so it can
+// be shown without revealing it
+func MaskKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
+
+// NewClient returns an API client configured from the global flags. It
+// fails when the stored API key cannot be decrypted.
+func NewClient() (*client.Client, error) {
+	apk, err := GetApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	c := client.New(GetHost(), apk)
+	c.Retry = client.RetryPolicy{
+		MaxAttempts:    Config.RetryMaxAttempts,
+		InitialBackoff: Config.RetryBackoff,
+		MaxBackoff:     Config.RetryMaxBackoff,
+		Jitter:         Config.RetryJitter,
+	}
+	c.StreamHeaderAuth = Config.WSHeaderAuth
+	c.OnRetry = func(attempt int, wait time.Duration, err error) {
+		fmt.Fprintf(os.Stderr, "request failed (attempt %d/%d): %s; retrying in %s\n",
+			attempt, Config.RetryMaxAttempts, err, wait.Round(time.Millisecond))
+	}
+	c.OnReconnect = func(stream string, attempt int, wait time.Duration, err error) {
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "stream %s reconnected\n", stream)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "stream %s disconnected: %s; reconnecting in %s (attempt %d)\n",
+			stream, err, wait.Round(time.Millisecond), attempt)
+	}
+	return c, nil
+}
+
+// NewRenderer returns a renderer writing to stdout in the format selected
+// with --output, applying --fields, --sort and --no-header. Streams render
+// every record as soon as it arrives unless they are sorted.
+func NewRenderer(stream bool) (format.Renderer, error) {
+	keys, err := format.ParseSort(Config.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return format.New(Config.Output, os.Stdout, format.Options{
+		Stream:   stream,
+		NoHeader: Config.NoHeader,
+		Fields:   Config.Fields,
+		Sort:     keys,
+	})
+}
+
+// NewStreamRenderer returns a renderer for realtime subscriptions, which
+// never end and so cannot be sorted
+func NewStreamRenderer() (format.Renderer, error) {
+	if Config.Sort != "" {
+		return nil, errors.New("--sort needs the complete result set and cannot be used with subscribe")
+	}
+	return NewRenderer(true)
 }
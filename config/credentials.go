@@ -0,0 +1,267 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Credential storage modes
+const (
+	// ModeMachine derives the encryption key from machine and user identifiers
+	ModeMachine = "machine"
+	// ModePassphrase derives the encryption key from a user supplied passphrase
+	ModePassphrase = "passphrase"
+)
+
+// Credentials holds API keys per profile, stored encrypted at rest
+type Credentials struct {
+	Mode string
+	Keys map[string]string
+	// passphrase is kept so the file can be re-encrypted on save
+	passphrase string
+}
+
+// credentialsFile is the on-disk format of the credentials store
+type credentialsFile struct {
+	Version    int    `json:"version"`
+	Mode       string `json:"mode"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// CredentialsPath returns the location of the encrypted credentials store
+func CredentialsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials.enc"), nil
+}
+
+// LoadCredentials decrypts the credentials store, returning an empty store
+// if none exists. Passphrase protected stores read the passphrase from
+// HEIMDAHL_PASSPHRASE or prompt for it.
+func LoadCredentials() (*Credentials, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Credentials{Mode: ModeMachine, Keys: map[string]string{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+
+	var f credentialsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse credentials %s: %w", path, err)
+	}
+
+	c.Mode = f.Mode
+	secret, err := c.secret(false)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := decrypt(secret, f.Salt, f.Nonce, f.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt credentials %s: wrong passphrase or credentials created on another machine", path)
+	}
+	if err := json.Unmarshal(plain, &c.Keys); err != nil {
+		return nil, fmt.Errorf("parse credentials: %w", err)
+	}
+	return c, nil
+}
+
+// SetMode changes how the store is encrypted on the next Save
+func (c *Credentials) SetMode(mode string) error {
+	if mode != ModeMachine && mode != ModePassphrase {
+		return fmt.Errorf("unknown credentials mode %q", mode)
+	}
+	if mode != c.Mode {
+		c.passphrase = ""
+	}
+	c.Mode = mode
+	return nil
+}
+
+// Save encrypts and writes the store, removing it when it holds no keys
+func (c *Credentials) Save() error {
+	path, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+
+	if len(c.Keys) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove credentials: %w", err)
+		}
+		return nil
+	}
+
+	secret, err := c.secret(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(c.Keys)
+	if err != nil {
+		return err
+	}
+	salt, nonce, ciphertext, err := encrypt(secret, plain)
+	if err != nil {
+		return fmt.Errorf("encrypt credentials: %w", err)
+	}
+
+	b, err := json.MarshalIndent(credentialsFile{
+		Version:    1,
+		Mode:       c.Mode,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	// write to a temporary file first so a failed write never leaves a
+	// truncated store behind and loses every key in it
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	return nil
+}
+
+// secret returns the material the encryption key is derived from
+func (c *Credentials) secret(confirm bool) ([]byte, error) {
+	switch c.Mode {
+	case ModeMachine:
+		return machineSecret(), nil
+	case ModePassphrase:
+		if c.passphrase == "" {
+			p, err := readPassphrase(confirm)
+			if err != nil {
+				return nil, err
+			}
+			c.passphrase = p
+		}
+		return []byte(c.passphrase), nil
+	}
+	return nil, fmt.Errorf("unknown credentials mode %q", c.Mode)
+}
+
+// machineSecret identifies the current user on the current machine. It keeps
+// keys unreadable when the file is copied elsewhere, but does not protect
+// against other processes of the same user; use a passphrase for that.
+func machineSecret() []byte {
+	var parts []string
+	for _, p := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if b, err := os.ReadFile(p); err == nil {
+			parts = append(parts, strings.TrimSpace(string(b)))
+			break
+		}
+	}
+	if h, err := os.Hostname(); err == nil {
+		parts = append(parts, h)
+	}
+	if u, err := user.Current(); err == nil {
+		parts = append(parts, u.Uid, u.HomeDir)
+	}
+	sum := sha256.Sum256([]byte("heimdahl-cli:" + strings.Join(parts, ":")))
+	return sum[:]
+}
+
+func readPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv("HEIMDAHL_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+
+	prompt := promptui.Prompt{Label: "Credentials passphrase", Mask: '*'}
+	p, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	if p == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+
+	if confirm {
+		prompt = promptui.Prompt{Label: "Repeat passphrase", Mask: '*'}
+		again, err := prompt.Run()
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		if again != p {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return p, nil
+}
+
+func deriveKey(secret, salt []byte) ([]byte, error) {
+	return scrypt.Key(secret, salt, 1<<15, 8, 1, 32)
+}
+
+func encrypt(secret, plain []byte) (salt, nonce, ciphertext []byte, err error) {
+	salt = make([]byte, 16)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := deriveKey(secret, salt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, nil, nil, err
+	}
+	return salt, nonce, gcm.Seal(nil, nonce, plain, nil), nil
+}
+
+func decrypt(secret, salt, nonce, ciphertext []byte) ([]byte, error) {
+	key, err := deriveKey(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
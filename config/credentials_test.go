@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plain := []byte(`{"default":"secret-key"}`)
+	salt, nonce, ciphertext, err := encrypt([]byte("passphrase"), plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, []byte("secret-key")) {
+		t.Error("ciphertext contains the key")
+	}
+
+	got, err := decrypt([]byte("passphrase"), salt, nonce, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Errorf("decrypt = %s, want %s", got, plain)
+	}
+
+	if _, err := decrypt([]byte("wrong"), salt, nonce, ciphertext); err == nil {
+		t.Error("decrypt with the wrong secret succeeded")
+	}
+}
+
+func TestCredentialsRoundTrip(t *testing.T) {
+	for _, mode := range []string{ModeMachine, ModePassphrase} {
+		t.Run(mode, func(t *testing.T) {
+			t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+			t.Setenv("HEIMDAHL_PASSPHRASE", "correct horse")
+
+			c, err := LoadCredentials()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SetMode(mode); err != nil {
+				t.Fatal(err)
+			}
+			c.Keys["default"] = "key-1"
+			c.Keys["staging"] = "key-2"
+			if err := c.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			path, _ := CredentialsPath()
+			if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("temporary credentials left behind: %v", err)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(b, []byte("key-1")) {
+				t.Error("credentials file contains a plaintext key")
+			}
+
+			loaded, err := LoadCredentials()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Mode != mode || loaded.Keys["default"] != "key-1" || loaded.Keys["staging"] != "key-2" {
+				t.Errorf("loaded %s %v, want %s with both keys", loaded.Mode, loaded.Keys, mode)
+			}
+		})
+	}
+}
+
+func TestCredentialsWrongPassphrase(t *testing.T) {
+	t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+	t.Setenv("HEIMDAHL_PASSPHRASE", "correct horse")
+
+	c := &Credentials{Mode: ModePassphrase, Keys: map[string]string{"default": "key-1"}}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HEIMDAHL_PASSPHRASE", "battery staple")
+	_, err := LoadCredentials()
+	if err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("LoadCredentials = %v, want wrong passphrase", err)
+	}
+
+	// the API key lookup reports the failure instead of exiting
+	Config.APIKey = ""
+	resolvedKey = ""
+	t.Setenv("HEIMDAHL_API_KEY", "")
+	if _, err := GetApiKey(); err == nil || !strings.Contains(err.Error(), "API key could not be loaded") {
+		t.Errorf("GetApiKey = %v, want the decryption error", err)
+	}
+	if _, err := NewClient(); err == nil {
+		t.Error("NewClient succeeded without a readable API key")
+	}
+}
+
+func TestCredentialsSaveEmptyRemovesStore(t *testing.T) {
+	t.Setenv("HEIMDAHL_CONFIG_DIR", t.TempDir())
+
+	c := &Credentials{Mode: ModeMachine, Keys: map[string]string{"default": "key-1"}}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	delete(c.Keys, "default")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := CredentialsPath()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("empty store not removed: %v", err)
+	}
+}
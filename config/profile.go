@@ -0,0 +1,247 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is used when no profile has been selected
+const DefaultProfile = "default"
+
+// Profile holds connection settings and command defaults for one environment.
+// Its API key is kept in the credentials store, see Credentials.
+type Profile struct {
+	Host    string `yaml:"host,omitempty"`
+	Secure  *bool  `yaml:"secure,omitempty"`
+	Chain   string `yaml:"chain,omitempty"`
+	Network string `yaml:"network,omitempty"`
+	Output  string `yaml:"output,omitempty"`
+}
+
+// ProfileKeys lists the settings that can be read and written with Get and Set
+var ProfileKeys = []string{"host", "secure", "chain", "network", "output"}
+
+// errAPIKeySetting is returned for the api_key setting, API keys are only
+// kept encrypted in the credentials store
+var errAPIKeySetting = errors.New(`the API key is not kept in the config file, store it with "heimdahl auth login"`)
+
+// Get returns the value of setting key
+func (p *Profile) Get(key string) (string, error) {
+	switch key {
+	case "host":
+		return p.Host, nil
+	case "secure":
+		if p.Secure == nil {
+			return "", nil
+		}
+		return strconv.FormatBool(*p.Secure), nil
+	case "api_key":
+		return "", errAPIKeySetting
+	case "chain":
+		return p.Chain, nil
+	case "network":
+		return p.Network, nil
+	case "output":
+		return p.Output, nil
+	}
+	return "", fmt.Errorf("unknown setting %q (valid: %v)", key, ProfileKeys)
+}
+
+// Set updates setting key, an empty value clears it
+func (p *Profile) Set(key, value string) error {
+	switch key {
+	case "host":
+		p.Host = value
+	case "secure":
+		if value == "" {
+			p.Secure = nil
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for secure: %q", value)
+		}
+		p.Secure = &b
+	case "api_key":
+		return errAPIKeySetting
+	case "chain":
+		p.Chain = value
+	case "network":
+		p.Network = value
+	case "output":
+		p.Output = value
+	default:
+		return fmt.Errorf("unknown setting %q (valid: %v)", key, ProfileKeys)
+	}
+	return nil
+}
+
+// File is the persistent CLI configuration
+type File struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Dir returns the directory holding CLI configuration, honouring
+// HEIMDAHL_CONFIG_DIR and XDG_CONFIG_HOME
+func Dir() (string, error) {
+	if d := os.Getenv("HEIMDAHL_CONFIG_DIR"); d != "" {
+		return d, nil
+	}
+	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+		return filepath.Join(d, "heimdahl"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "heimdahl"), nil
+}
+
+// FilePath returns the location of the config file
+func FilePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// LoadFile reads the config file, returning an empty config if it does not exist
+func LoadFile() (*File, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Profiles: map[string]*Profile{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Profile{}
+	}
+	return f, nil
+}
+
+// Save writes the config file, readable only by the current user
+func (f *File) Save() error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// ProfileNames returns all profile names in sorted order
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the named profile, creating it if create is set
+func (f *File) Profile(name string, create bool) (*Profile, bool) {
+	p, ok := f.Profiles[name]
+	if !ok && create {
+		p = &Profile{}
+		f.Profiles[name] = p
+	}
+	return p, ok || create
+}
+
+// ProfileName resolves the active profile: the --profile flag, then the
+// HEIMDAHL_PROFILE environment variable, then the config file selection
+func (f *File) ProfileName() string {
+	if Config.Profile != "" {
+		return Config.Profile
+	}
+	if p := os.Getenv("HEIMDAHL_PROFILE"); p != "" {
+		return p
+	}
+	if f.CurrentProfile != "" {
+		return f.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// ProfileName returns the name of the active profile once ApplyProfile has run
+func ProfileName() string {
+	if Config.Profile != "" {
+		return Config.Profile
+	}
+	return DefaultProfile
+}
+
+// ApplyProfile loads the active profile and uses its values for every flag in
+// flags that was not set explicitly on the command line
+func ApplyProfile(flags *pflag.FlagSet) error {
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+
+	name := f.ProfileName()
+	Config.Profile = name
+	p, ok := f.Profile(name, false)
+	if !ok {
+		if name != DefaultProfile {
+			return fmt.Errorf("profile %q not found in config", name)
+		}
+		return nil
+	}
+
+	secure := ""
+	if p.Secure != nil {
+		secure = strconv.FormatBool(*p.Secure)
+	}
+
+	defaults := map[string]string{
+		"host":    p.Host,
+		"secure":  secure,
+		"chain":   p.Chain,
+		"network": p.Network,
+		"output":  p.Output,
+	}
+	for name, value := range defaults {
+		flag := flags.Lookup(name)
+		if value == "" || flag == nil || flag.Changed {
+			continue
+		}
+		// --format is the deprecated spelling of --output
+		if name == "output" && flags.Changed("format") {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("profile %q: invalid %s %q: %w", Config.Profile, name, value, err)
+		}
+	}
+	return nil
+}
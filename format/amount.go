@@ -0,0 +1,36 @@
+package format
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// Amount is a raw token amount together with the token's decimals. It is
+// rendered as an exact decimal string.
+type Amount struct {
+	Value    *big.Int
+	Decimals int
+}
+
+func (a Amount) String() string {
+	return FormatAmountBigInt(a.Value, uint8(a.Decimals))
+}
+
+// MarshalJSON encodes the amount as a decimal string so no precision is lost
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// MarshalYAML encodes the amount as a decimal string
+func (a Amount) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+
+// Rat returns the amount scaled by its decimals
+func (a Amount) Rat() *big.Rat {
+	if a.Value == nil {
+		return new(big.Rat)
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.Decimals)), nil)
+	return new(big.Rat).SetFrac(a.Value, denom)
+}
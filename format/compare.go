@@ -0,0 +1,69 @@
+package format
+
+import (
+	"encoding/json"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var decimalRe = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Rat converts numeric values, including decimal strings, to exact
+// rationals. Token amounts are scaled by their decimals.
+func Rat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case *big.Rat:
+		return n, true
+	case Amount:
+		if n.Value == nil {
+			return nil, false
+		}
+		return n.Rat(), true
+	case *big.Int:
+		if n == nil {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(n), true
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint64:
+		return new(big.Rat).SetUint64(n), true
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil {
+			return nil, false
+		}
+		return r, true
+	case json.Number:
+		return new(big.Rat).SetString(n.String())
+	case string:
+		if decimalRe.MatchString(n) {
+			return new(big.Rat).SetString(n)
+		}
+	}
+	return nil, false
+}
+
+// Compare orders two field values: numbers numerically, times
+// chronologically and anything else as case insensitive text
+func Compare(a, b interface{}) int {
+	if ra, ok := Rat(a); ok {
+		if rb, ok := Rat(b); ok {
+			return ra.Cmp(rb)
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	return strings.Compare(strings.ToLower(Cell(a)), strings.ToLower(Cell(b)))
+}
@@ -0,0 +1,54 @@
+package format
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+func init() {
+	Register("csv", func(w io.Writer, opts Options) Renderer {
+		return newDelimitedRenderer(w, ',', opts)
+	})
+	Register("tsv", func(w io.Writer, opts Options) Renderer {
+		return newDelimitedRenderer(w, '\t', opts)
+	})
+}
+
+// delimitedRenderer writes records as CSV or TSV with a single header row
+type delimitedRenderer struct {
+	w        *csv.Writer
+	stream   bool
+	noHeader bool
+	columns  []string
+}
+
+func newDelimitedRenderer(w io.Writer, comma rune, opts Options) *delimitedRenderer {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &delimitedRenderer{w: cw, stream: opts.Stream, noHeader: opts.NoHeader}
+}
+
+func (d *delimitedRenderer) Write(r Record) error {
+	if d.columns == nil {
+		d.columns = r.Names()
+		if !d.noHeader {
+			if err := d.w.Write(d.columns); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := d.w.Write(cells(r, d.columns)); err != nil {
+		return err
+	}
+	if d.stream {
+		d.w.Flush()
+		return d.w.Error()
+	}
+	return nil
+}
+
+func (d *delimitedRenderer) Close() error {
+	d.w.Flush()
+	return d.w.Error()
+}
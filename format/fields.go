@@ -0,0 +1,146 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey orders records by a field, optionally descending
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a sort specification such as "amount:desc,timestamp"
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, dir, _ := strings.Cut(part, ":")
+		key := SortKey{Field: strings.TrimSpace(field)}
+		switch strings.ToLower(strings.TrimSpace(dir)) {
+		case "", "asc":
+		case "desc":
+			key.Desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q for %s (use asc or desc)", dir, key.Field)
+		}
+		if key.Field == "" {
+			return nil, fmt.Errorf("invalid sort key %q", part)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// projectRenderer keeps only the selected fields of every record, in the
+// order they were given
+type projectRenderer struct {
+	next    Renderer
+	fields  []string
+	checked bool
+}
+
+func (p *projectRenderer) Write(r Record) error {
+	if !p.checked {
+		// dotted names reach into values that may be missing on some
+		// records, so only plain names are checked
+		for _, name := range p.fields {
+			if _, ok := r.Get(name); !ok && !strings.Contains(name, ".") {
+				return fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(r.Names(), ", "))
+			}
+		}
+		p.checked = true
+	}
+
+	projected := make(Record, len(p.fields))
+	for i, name := range p.fields {
+		v, _ := r.Get(name)
+		projected[i] = Field{Name: name, Value: v}
+	}
+	return p.next.Write(projected)
+}
+
+func (p *projectRenderer) Close() error {
+	return p.next.Close()
+}
+
+// sortRenderer buffers every record and writes them in order on Close
+type sortRenderer struct {
+	next    Renderer
+	keys    []SortKey
+	records []Record
+}
+
+func (s *sortRenderer) Write(r Record) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+// Close writes the sorted records and always closes the wrapped renderer, so
+// its output is flushed even when sorting fails
+func (s *sortRenderer) Close() error {
+	return errors.Join(s.flush(), s.next.Close())
+}
+
+func (s *sortRenderer) flush() error {
+	if len(s.records) > 0 {
+		for _, key := range s.keys {
+			if !s.known(key.Field) {
+				return fmt.Errorf("unknown sort field %q (available: %s)", key.Field, strings.Join(s.records[0].Names(), ", "))
+			}
+		}
+	}
+
+	sort.SliceStable(s.records, func(i, j int) bool {
+		return s.less(s.records[i], s.records[j])
+	})
+	for _, r := range s.records {
+		if err := s.next.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sortRenderer) known(field string) bool {
+	for _, r := range s.records {
+		if _, ok := r.Get(field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// less compares records key by key. Records missing a field sort last
+// whatever the direction.
+func (s *sortRenderer) less(a, b Record) bool {
+	for _, key := range s.keys {
+		va, oka := a.Get(key.Field)
+		vb, okb := b.Get(key.Field)
+		oka, okb = oka && va != nil, okb && vb != nil
+		switch {
+		case !oka && !okb:
+			continue
+		case !oka:
+			return false
+		case !okb:
+			return true
+		}
+
+		c := Compare(va, vb)
+		if c == 0 {
+			continue
+		}
+		if key.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+)
+
+func init() {
+	Register("json", func(w io.Writer, opts Options) Renderer {
+		return &jsonRenderer{w: w}
+	})
+	Register("ndjson", func(w io.Writer, opts Options) Renderer {
+		return &ndjsonRenderer{enc: json.NewEncoder(w)}
+	})
+}
+
+// jsonRenderer writes records as an indented JSON array, element by element
+type jsonRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonRenderer) Write(r Record) error {
+	b, err := json.MarshalIndent(r, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n  "
+	if j.count == 0 {
+		prefix = "[\n  "
+	}
+	j.count++
+
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonRenderer) Close() error {
+	trailer := "\n]\n"
+	if j.count == 0 {
+		trailer = "[]\n"
+	}
+	_, err := io.WriteString(j.w, trailer)
+	return err
+}
+
+// ndjsonRenderer writes one JSON object per line
+type ndjsonRenderer struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonRenderer) Write(r Record) error {
+	return n.enc.Encode(r)
+}
+
+func (n *ndjsonRenderer) Close() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	Register("markdown", func(w io.Writer, opts Options) Renderer {
+		return &markdownRenderer{w: w, noHeader: opts.NoHeader}
+	})
+}
+
+// markdownRenderer writes records as a GitHub flavoured markdown table
+type markdownRenderer struct {
+	w        io.Writer
+	noHeader bool
+	columns  []string
+}
+
+func (m *markdownRenderer) Write(r Record) error {
+	if m.columns == nil {
+		m.columns = r.Names()
+		if !m.noHeader {
+			if err := m.writeHeader(); err != nil {
+				return err
+			}
+		}
+	}
+	return m.writeRow(cells(r, m.columns))
+}
+
+func (m *markdownRenderer) writeHeader() error {
+	header := make([]string, len(m.columns))
+	rule := make([]string, len(m.columns))
+	for i, c := range m.columns {
+		header[i] = Header(c)
+		rule[i] = "---"
+	}
+	if err := m.writeRow(header); err != nil {
+		return err
+	}
+	return m.writeRow(rule)
+}
+
+func (m *markdownRenderer) writeRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, c := range row {
+		c = strings.ReplaceAll(c, "|", `\|`)
+		escaped[i] = strings.ReplaceAll(c, "\n", " ")
+	}
+	_, err := fmt.Fprintf(m.w, "| %s |\n", strings.Join(escaped, " | "))
+	return err
+}
+
+func (m *markdownRenderer) Close() error {
+	return nil
+}
@@ -0,0 +1,151 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeLayout is used to render timestamps in human readable outputs
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Field is a single named value of a record
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Record is an ordered set of fields rendered as a table row or an object
+type Record []Field
+
+// Names returns the field names in order
+func (r Record) Names() []string {
+	names := make([]string, len(r))
+	for i, f := range r {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Get returns the value of the named field. Dotted names look up keys of
+// map values, eg. "args.value".
+func (r Record) Get(name string) (interface{}, bool) {
+	for _, f := range r {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+
+	head, rest, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, false
+	}
+	v, ok := r.Get(head)
+	if !ok {
+		return nil, false
+	}
+	for _, key := range strings.Split(rest, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// MarshalJSON encodes the record as an object preserving field order
+func (r Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Cell renders a field value as text for tabular outputs
+func Cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *big.Int:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(TimeLayout)
+	case fmt.Stringer:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ", ")
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = Cell(e)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%s: %s", k, Cell(v[k]))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// Header returns the column title for a field name
+func Header(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "_", " "))
+}
+
+// cells renders the values of r for the given columns
+func cells(r Record, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		if v, ok := r.Get(c); ok {
+			row[i] = Cell(v)
+		}
+	}
+	return row
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
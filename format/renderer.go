@@ -0,0 +1,80 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Renderer writes records in a particular output format
+type Renderer interface {
+	// Write renders a single record
+	Write(r Record) error
+	// Close flushes buffered output and writes any trailer
+	Close() error
+}
+
+// Options control how records are rendered
+type Options struct {
+	// Stream renders every record as soon as it is written instead of
+	// buffering, as required for realtime subscriptions
+	Stream bool
+	// NoHeader omits the header row of tabular formats, e.g. when appending
+	// to existing output
+	NoHeader bool
+	// Fields selects and orders the fields rendered, all fields are
+	// rendered when empty
+	Fields []string
+	// Sort orders records before rendering. Sorting needs every record, so
+	// output is buffered until Close even when streaming.
+	Sort []SortKey
+}
+
+// Factory creates a renderer writing to w
+type Factory func(w io.Writer, opts Options) Renderer
+
+var registry = map[string]Factory{}
+
+// Register makes an output format available under name
+func Register(name string, f Factory) {
+	registry[name] = f
+}
+
+// Names returns the registered output formats in sorted order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Nested reports whether the named output format renders nested records and
+// lists as structures, not as text cells like the tabular formats
+func Nested(name string) bool {
+	switch strings.ToLower(name) {
+	case "json", "yaml":
+		return true
+	}
+	return false
+}
+
+// New creates a renderer for the named output format
+func New(name string, w io.Writer, opts Options) (Renderer, error) {
+	f, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	r := f(w, opts)
+	if len(opts.Fields) > 0 {
+		r = &projectRenderer{next: r, fields: opts.Fields}
+	}
+	if len(opts.Sort) > 0 {
+		// sort before projecting so records can be ordered by fields that
+		// are not shown
+		r = &sortRenderer{next: r, keys: opts.Sort}
+	}
+	return r, nil
+}
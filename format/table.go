@@ -0,0 +1,107 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	Register("table", func(w io.Writer, opts Options) Renderer {
+		return &tableRenderer{w: w, stream: opts.Stream, noHeader: opts.NoHeader}
+	})
+}
+
+// tableRenderer aligns records into columns. Buffered tables size columns to
+// fit every row; streamed tables size them from the header and first row.
+type tableRenderer struct {
+	w        io.Writer
+	stream   bool
+	noHeader bool
+	columns  []string
+	widths   []int
+	rows     [][]string
+}
+
+func (t *tableRenderer) Write(r Record) error {
+	if t.columns == nil {
+		t.columns = r.Names()
+		t.widths = make([]int, len(t.columns))
+		for i, c := range t.columns {
+			t.widths[i] = utf8.RuneCountInString(Header(c))
+		}
+	}
+
+	row := cells(r, t.columns)
+	if !t.stream {
+		t.fit(row)
+		t.rows = append(t.rows, row)
+		return nil
+	}
+
+	if len(t.rows) == 0 {
+		t.fit(row)
+		t.rows = append(t.rows, nil)
+		if err := t.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return t.writeRow(row)
+}
+
+func (t *tableRenderer) Close() error {
+	if t.stream || t.columns == nil {
+		return nil
+	}
+	if err := t.writeHeader(); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := t.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tableRenderer) fit(row []string) {
+	for i, c := range row {
+		if n := utf8.RuneCountInString(c); n > t.widths[i] {
+			t.widths[i] = n
+		}
+	}
+}
+
+func (t *tableRenderer) writeHeader() error {
+	if t.noHeader {
+		return nil
+	}
+	header := make([]string, len(t.columns))
+	rule := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = Header(c)
+		rule[i] = strings.Repeat("-", t.widths[i])
+	}
+	if err := t.writeRow(header); err != nil {
+		return err
+	}
+	return t.writeRow(rule)
+}
+
+func (t *tableRenderer) writeRow(row []string) error {
+	var b strings.Builder
+	for i, c := range row {
+		if i > 0 {
+			b.WriteString(" | ")
+		}
+		b.WriteString(c)
+		if i < len(row)-1 {
+			if pad := t.widths[i] - utf8.RuneCountInString(c); pad > 0 {
+				b.WriteString(strings.Repeat(" ", pad))
+			}
+		}
+	}
+	_, err := fmt.Fprintln(t.w, b.String())
+	return err
+}
@@ -0,0 +1,99 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register("yaml", func(w io.Writer, opts Options) Renderer {
+		return &yamlRenderer{w: w}
+	})
+}
+
+// yamlRenderer writes records as a YAML sequence, item by item
+type yamlRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (y *yamlRenderer) Write(r Record) error {
+	node, err := yamlNode(r)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal([]*yaml.Node{node})
+	if err != nil {
+		return err
+	}
+	y.count++
+	_, err = y.w.Write(b)
+	return err
+}
+
+func (y *yamlRenderer) Close() error {
+	if y.count == 0 {
+		_, err := io.WriteString(y.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// yamlNode converts a value into a YAML node, keeping record field order and
+// big integers exact
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case Record:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, f := range v {
+			value, err := yamlNode(f.Value)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Name}, value)
+		}
+		return node, nil
+	case *big.Int:
+		if v == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v.String()}, nil
+	case json.Number:
+		tag := "!!int"
+		if _, err := v.Int64(); err != nil {
+			if _, ok := new(big.Int).SetString(v.String(), 10); !ok {
+				tag = "!!float"
+			}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range sortedKeys(v) {
+			value, err := yamlNode(v[k])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, value)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, e := range v {
+			value, err := yamlNode(e)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
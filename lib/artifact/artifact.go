@@ -0,0 +1,44 @@
+// Package artifact reads contract ABIs and deployments from Foundry and
+// Hardhat build output
+package artifact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ABI returns the ABI held by b, which may be a bare ABI array or a build
+// artifact with an abi field: a Foundry out/*.json artifact, a Hardhat
+// artifacts/**.json artifact or a hardhat-deploy deployment
+func ABI(b []byte) (json.RawMessage, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		return b, nil
+	}
+
+	var art struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(b, &art); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+	raw := bytes.TrimSpace(art.ABI)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("no ABI found, expected an ABI array or a Foundry or Hardhat artifact")
+	}
+
+	// solc combined JSON and older tools keep the ABI as a string
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("invalid ABI: %w", err)
+		}
+		raw = bytes.TrimSpace([]byte(s))
+	}
+	if len(raw) == 0 || raw[0] != '[' {
+		return nil, errors.New("invalid ABI: expected a JSON array")
+	}
+	return raw, nil
+}
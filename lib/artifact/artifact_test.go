@@ -0,0 +1,104 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const abi = `[{"type": "event", "name": "Transfer", "inputs": []}]`
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestABI(t *testing.T) {
+	tests := map[string]string{
+		"bare":     "  " + abi + "\n",
+		"foundry":  `{"abi": ` + abi + `, "bytecode": {"object": "0x00"}}`,
+		"hardhat":  `{"_format": "hh-sol-artifact-1", "contractName": "Token", "abi": ` + abi + `}`,
+		"solc":     `{"abi": "` + strings.ReplaceAll(abi, `"`, `\"`) + `"}`,
+		"deployed": `{"address": "0x01", "abi": ` + abi + `}`,
+	}
+	for name, in := range tests {
+		got, err := ABI([]byte(in))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != abi {
+			t.Errorf("%s: ABI = %s, want %s", name, got, abi)
+		}
+	}
+}
+
+func TestABIErrors(t *testing.T) {
+	for _, in := range []string{`{}`, `{"abi": null}`, `{"abi": `, `not json`, `{"abi": 5}`, `{"abi": {"type": "event"}}`, `{"abi": "not an array"}`} {
+		if got, err := ABI([]byte(in)); err == nil {
+			t.Errorf("ABI(%s) = %s, want an error", in, got)
+		}
+	}
+}
+
+func TestFoundryBroadcast(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "out", "Token.sol", "Token.json"), `{"abi": `+abi+`}`)
+	write(t, filepath.Join(root, "out", "Vault.sol", "Vault.json"), `{"abi": []}`)
+	path := filepath.Join(root, "broadcast", "Deploy.s.sol", "1", "run-latest.json")
+	write(t, path, `{"chain": 1, "transactions": [
+		{"transactionType": "CREATE", "contractName": "Token", "contractAddress": "0xAA"},
+		{"transactionType": "CALL", "contractName": "Token", "contractAddress": "0xAA"},
+		{"transactionType": "CREATE2", "contractName": "Vault", "contractAddress": "0xBB"},
+		{"transactionType": "CREATE", "contractName": "Token", "contractAddress": "0xaa"}]}`)
+
+	p, err := Read(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ChainID != 1 || len(p.Deployments) != 2 {
+		t.Fatalf("project = %+v, want chain 1 with 2 deployments", p)
+	}
+	if d := p.Deployments[0]; d.Name != "Token" || d.Address != "0xAA" || string(d.ABI) != abi {
+		t.Errorf("deployment = %+v", d)
+	}
+	if d := p.Deployments[1]; d.Name != "Vault" || d.Address != "0xBB" {
+		t.Errorf("deployment = %+v", d)
+	}
+
+	if _, err := Read(path, t.TempDir()); err == nil || !strings.Contains(err.Error(), "set --artifacts") {
+		t.Errorf("Read without artifacts err = %v", err)
+	}
+}
+
+func TestHardhatDeployments(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, ".chainId"), "8453\n")
+	write(t, filepath.Join(dir, "Token.json"), `{"address": "0xAA", "abi": `+abi+`}`)
+	write(t, filepath.Join(dir, "Library.json"), `{"abi": []}`)
+	write(t, filepath.Join(dir, "Vault.json"), `{"address": "0xBB", "abi": []}`)
+
+	p, err := Read(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ChainID != 8453 || len(p.Deployments) != 2 {
+		t.Fatalf("project = %+v, want chain 8453 with 2 deployments", p)
+	}
+	if d := p.Deployments[0]; d.Name != "Token" || d.Address != "0xAA" || string(d.ABI) != abi {
+		t.Errorf("deployment = %+v", d)
+	}
+	if d := p.Deployments[1]; d.Name != "Vault" || d.Address != "0xBB" {
+		t.Errorf("deployment = %+v", d)
+	}
+
+	if _, err := Read(t.TempDir(), ""); err == nil || !strings.Contains(err.Error(), "no deployments") {
+		t.Errorf("Read of an empty directory err = %v", err)
+	}
+}
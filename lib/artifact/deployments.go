@@ -0,0 +1,152 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Deployment is a contract deployed by a Foundry script or hardhat-deploy
+type Deployment struct {
+	Name    string
+	Address string
+	ABI     json.RawMessage
+}
+
+// Project is the set of contracts deployed to one chain
+type Project struct {
+	// ChainID is the chain the contracts were deployed to, 0 when unknown
+	ChainID     int64
+	Deployments []Deployment
+}
+
+// Read reads deployments from a Foundry broadcast file, eg.
+// broadcast/Deploy.s.sol/1/run-latest.json, or a hardhat-deploy directory,
+// eg. deployments/mainnet. The ABIs of Foundry deployments are read from the
+// artifacts directory, by default the out directory of the project.
+func Read(path, artifacts string) (*Project, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return HardhatDeployments(path)
+	}
+	if artifacts == "" {
+		// broadcast/<script>/<chain id>/run-latest.json
+		artifacts = filepath.Join(filepath.Dir(path), "..", "..", "..", "out")
+	}
+	return FoundryBroadcast(path, artifacts)
+}
+
+// FoundryBroadcast reads the contracts created by a Foundry script run
+func FoundryBroadcast(path, artifacts string) (*Project, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var run struct {
+		Chain        int64 `json:"chain"`
+		Transactions []struct {
+			Type    string `json:"transactionType"`
+			Name    string `json:"contractName"`
+			Address string `json:"contractAddress"`
+		} `json:"transactions"`
+	}
+	if err := json.Unmarshal(b, &run); err != nil {
+		return nil, fmt.Errorf("parse broadcast %s: %w", path, err)
+	}
+
+	p := &Project{ChainID: run.Chain}
+	seen := map[string]bool{}
+	for _, tx := range run.Transactions {
+		if (tx.Type != "CREATE" && tx.Type != "CREATE2") || tx.Name == "" || tx.Address == "" {
+			continue
+		}
+		key := strings.ToLower(tx.Address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		abi, err := foundryABI(artifacts, tx.Name)
+		if err != nil {
+			return nil, err
+		}
+		p.Deployments = append(p.Deployments, Deployment{Name: tx.Name, Address: tx.Address, ABI: abi})
+	}
+	if len(p.Deployments) == 0 {
+		return nil, fmt.Errorf("no contracts created in %s", path)
+	}
+	return p, nil
+}
+
+// foundryABI reads the ABI of a contract from out/<source>.sol/<name>.json
+func foundryABI(artifacts, name string) (json.RawMessage, error) {
+	matches, err := filepath.Glob(filepath.Join(artifacts, "*", name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no artifact for %s in %s, set --artifacts to the Foundry out directory", name, artifacts)
+	case 1:
+	default:
+		return nil, fmt.Errorf("several artifacts for %s: %s", name, strings.Join(matches, ", "))
+	}
+
+	b, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil, err
+	}
+	abi, err := ABI(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", matches[0], err)
+	}
+	return abi, nil
+}
+
+// HardhatDeployments reads the contracts of a hardhat-deploy network
+// directory: one <name>.json per contract with its address and ABI, and the
+// chain id in .chainId
+func HardhatDeployments(dir string) (*Project, error) {
+	p := &Project{}
+	if b, err := os.ReadFile(filepath.Join(dir, ".chainId")); err == nil {
+		p.ChainID, _ = strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var d struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, fmt.Errorf("parse deployment %s: %w", path, err)
+		}
+		if d.Address == "" {
+			continue
+		}
+		abi, err := ABI(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		p.Deployments = append(p.Deployments, Deployment{Name: name, Address: d.Address, ABI: abi})
+	}
+	if len(p.Deployments) == 0 {
+		return nil, fmt.Errorf("no deployments found in %s", dir)
+	}
+	return p, nil
+}
@@ -0,0 +1,185 @@
+// Package client provides a typed client for the Heimdahl REST API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single HTTP round trip
+const DefaultTimeout = 30 * time.Second
+
+// Client performs authenticated requests against the Heimdahl API
+type Client struct {
+	// BaseURL is the scheme and host of the API server (eg. https://api.heimdahl.xyz)
+	BaseURL string
+	// APIKey is sent as a bearer token with every request
+	APIKey string
+	// HTTPClient is used to perform requests
+	HTTPClient *http.Client
+	// StreamHeaderAuth sends the API key in the websocket handshake headers
+	// instead of the URL query string
+	StreamHeaderAuth bool
+	// Retry controls retries of failed requests
+	Retry RetryPolicy
+	// OnRetry, if set, is called before waiting to retry a failed request
+	OnRetry func(attempt int, wait time.Duration, err error)
+	// OnReconnect, if set, is called with the error that dropped a stream
+	// before waiting to reconnect, and with a nil error once reconnected
+	OnReconnect func(stream string, attempt int, wait time.Duration, err error)
+
+	// pauseUntil holds back every request of this client after the server
+	// rate limited one of them, so concurrent requests back off together
+	mu         sync.Mutex
+	pauseUntil time.Time
+}
+
+// ListOptions controls paging of list endpoints
+type ListOptions struct {
+	Page    int
+	PerPage int
+	// Filter holds additional query parameters, eg. a time range
+	Filter url.Values
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	for k, vals := range o.Filter {
+		v[k] = vals
+	}
+	v.Set("page", strconv.Itoa(o.Page))
+	if o.PerPage > 0 {
+		v.Set("pageSize", strconv.Itoa(o.PerPage))
+	}
+	return v
+}
+
+// New creates a client for the API server at baseURL
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+// Get performs a GET request for path and decodes the JSON response into out
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	_, err := c.do(ctx, http.MethodGet, path, query, nil, out)
+	return err
+}
+
+// do performs a request and decodes a successful JSON response into out,
+// retrying transient failures according to the client's RetryPolicy.
+// Non-2xx responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) (int, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.waitPause(ctx); err != nil {
+			return 0, err
+		}
+
+		status, err := c.doOnce(ctx, method, u, payload, out)
+		if err == nil || attempt >= attempts || !shouldRetry(method, err) {
+			return status, err
+		}
+
+		wait := c.Retry.delay(attempt, err)
+		if apiErr, ok := AsAPIError(err); ok && apiErr.IsRateLimited() {
+			c.pause(wait)
+		}
+		if c.OnRetry != nil {
+			c.OnRetry(attempt, wait, err)
+		}
+		if serr := sleep(ctx, wait); serr != nil {
+			return status, err
+		}
+	}
+}
+
+// doOnce performs a single HTTP round trip
+func (c *Client) doOnce(ctx context.Context, method, u string, payload []byte, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, newAPIError(resp, b)
+	}
+
+	if out != nil && len(bytes.TrimSpace(b)) > 0 {
+		if err := json.Unmarshal(b, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// pause holds back every request of this client for d, eg. once the server
+// rate limited a request. A longer pause already in place is kept.
+func (c *Client) pause(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(d); until.After(c.pauseUntil) {
+		c.pauseUntil = until
+	}
+}
+
+// waitPause blocks until a pause set after a rate limited request has passed
+func (c *Client) waitPause(ctx context.Context) error {
+	c.mu.Lock()
+	until := c.pauseUntil
+	c.mu.Unlock()
+	return sleep(ctx, time.Until(until))
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
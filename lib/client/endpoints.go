@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+)
+
+// ListTransfers returns a page of fungible token transfers matching pattern
+func (c *Client) ListTransfers(ctx context.Context, pattern string, opts ListOptions) (*lib.TokenResponse, error) {
+	var resp lib.TokenResponse
+	if err := c.Get(ctx, "/v1/transfers/list/"+url.PathEscape(pattern), opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListSwaps returns a page of token swaps matching pattern
+func (c *Client) ListSwaps(ctx context.Context, pattern string, opts ListOptions) (*lib.SwapData, error) {
+	var resp lib.SwapData
+	if err := c.Get(ctx, "/v1/swaps/list/"+url.PathEscape(pattern), opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListEvents returns a page of contract events matching pattern
+func (c *Client) ListEvents(ctx context.Context, pattern string, opts ListOptions) (*lib.EventDetails, error) {
+	var resp lib.EventDetails
+	if err := c.Get(ctx, "/v1/events/list/"+url.PathEscape(pattern), opts.values(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListChains returns all supported chains
+func (c *Client) ListChains(ctx context.Context) ([]lib.ChainInfo, error) {
+	var chains []lib.ChainInfo
+	if err := c.Get(ctx, "/v1/chains", nil, &chains); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GetChain returns information about a single chain network
+func (c *Client) GetChain(ctx context.Context, chain, network string) (*lib.ChainInfo, error) {
+	var info lib.ChainInfo
+	if err := c.Get(ctx, "/v1/chains/"+url.PathEscape(chain)+"/"+url.PathEscape(network), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListContracts returns contracts indexed on the given chain network
+func (c *Client) ListContracts(ctx context.Context, chain, network string) ([]lib.ContractInfo, error) {
+	var contracts []lib.ContractInfo
+	if err := c.Get(ctx, "/v1/contracts", networkQuery(chain, network), &contracts); err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// GetContract returns a contract indexed on the given chain network by
+// address. The same address may be deployed on several EVM chains.
+func (c *Client) GetContract(ctx context.Context, chain, network, address string) (*lib.ContractInfo, error) {
+	var info lib.ContractInfo
+	if err := c.Get(ctx, "/v1/contracts/"+url.PathEscape(address), networkQuery(chain, network), &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// AddContract registers a contract for indexing. It reports false when the
+// contract was already registered.
+func (c *Client) AddContract(ctx context.Context, params lib.ContractParams) (bool, error) {
+	status, err := c.do(ctx, http.MethodPost, "/v1/contracts", nil, params, nil)
+	if err != nil {
+		return false, err
+	}
+	return status == http.StatusCreated, nil
+}
+
+// UpdateContract changes a contract registered on the given chain network
+func (c *Client) UpdateContract(ctx context.Context, chain, network, address string, update lib.ContractUpdate) error {
+	_, err := c.do(ctx, http.MethodPatch, "/v1/contracts/"+url.PathEscape(address), networkQuery(chain, network), update, nil)
+	return err
+}
+
+// RemoveContract stops indexing a contract registered on the given chain
+// network
+func (c *Client) RemoveContract(ctx context.Context, chain, network, address string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/v1/contracts/"+url.PathEscape(address), networkQuery(chain, network), nil, nil)
+	return err
+}
+
+func networkQuery(chain, network string) url.Values {
+	q := url.Values{}
+	q.Set("chain", chain)
+	q.Set("network", network)
+	return q
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned for responses with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Status     string
+	// RequestID is the server-assigned request identifier, if any
+	RequestID string
+	// Message is the error reported by the server, if any
+	Message string
+	// Body is the raw response body
+	Body string
+	// Retryable reports whether repeating the request may succeed
+	Retryable bool
+	// RetryAfter is the delay requested by the server before retrying
+	RetryAfter time.Duration
+	// RateLimitReset is when the current rate limit window resets
+	RateLimitReset time.Time
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error: %s", e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request id " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// IsUnauthorized reports whether the API key was missing, invalid or lacks access
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the requested resource does not exist
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited reports whether the request was rejected by rate limiting
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsServerError reports whether the server failed to handle the request
+func (e *APIError) IsServerError() bool {
+	return e.StatusCode >= 500
+}
+
+// AsAPIError returns the *APIError wrapped in err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// ErrorFromResponse builds an *APIError from a failed response, reading and
+// closing its body. It is used for websocket handshakes as well as REST calls.
+func ErrorFromResponse(resp *http.Response) *APIError {
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+	}
+	return newAPIError(resp, body)
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	now := time.Now()
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  requestID(resp.Header),
+		Message:    errorMessage(body),
+		Body:       string(body),
+		Retryable:  isRetryableStatus(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+	}
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	e.RateLimitReset = parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now)
+	if e.RateLimitReset.IsZero() && e.RetryAfter > 0 {
+		e.RateLimitReset = now.Add(e.RetryAfter)
+	}
+	if e.RetryAfter == 0 && !e.RateLimitReset.IsZero() && e.IsRateLimited() {
+		e.RetryAfter = e.RateLimitReset.Sub(now)
+	}
+
+	return e
+}
+
+func requestID(h http.Header) string {
+	for _, k := range []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Trace-Id"} {
+		if v := h.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooEarly,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter accepts both delay-seconds and HTTP-date forms
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// parseRateLimitReset accepts a unix timestamp or a number of seconds until reset
+func parseRateLimitReset(v string, now time.Time) time.Time {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	// values smaller than a day are relative delays rather than timestamps
+	if n < 86400 {
+		return now.Add(time.Duration(n) * time.Second)
+	}
+	return time.Unix(n, 0)
+}
+
+// errorMessage extracts the error text from a response body, accepting both
+// {"error": "..."} / {"message": "..."} JSON payloads and plain text
+func errorMessage(body []byte) string {
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Error != "" {
+			return payload.Error
+		}
+		if payload.Message != "" {
+			return payload.Message
+		}
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > 512 {
+		msg = msg[:512] + "..."
+	}
+	return msg
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStop may be returned by the emit func of Paginate to stop after the
+// current item, eg. once the items have moved past a time range
+var ErrStop = errors.New("stop pagination")
+
+// ErrSkip may be returned by the emit func of Paginate for items that were
+// filtered out and do not count towards the limit
+var ErrSkip = errors.New("skip item")
+
+// PageOptions controls walking a paginated list endpoint
+type PageOptions struct {
+	// StartPage is the first page to fetch
+	StartPage int
+	// PerPage is the number of items requested per page
+	PerPage int
+	// All walks pages until the total reported by the server is reached
+	All bool
+	// Limit stops after this many items, 0 means no limit
+	Limit int
+	// Filtered is set when emit may return ErrSkip. Skipped items do not
+	// count towards Limit, so the pages fetched are not bounded by it.
+	Filtered bool
+	// Concurrency is the number of pages fetched in parallel once the total
+	// is known; values below 2 fetch pages one at a time
+	Concurrency int
+	// AfterPage is called once all items of a page have been emitted, with
+	// the page number and the total reported by the server
+	AfterPage func(page, total int) error
+}
+
+// Multipage reports whether more than the start page may be fetched
+func (o PageOptions) Multipage() bool {
+	return o.All || o.Limit > 0
+}
+
+// PageFunc fetches a single page, returning its items and the total number
+// of items reported by the server. It may be called concurrently when
+// PageOptions.Concurrency is above 1.
+type PageFunc[T any] func(ctx context.Context, page int) ([]T, int, error)
+
+// Paginate fetches pages starting at opts.StartPage and passes every item to
+// emit in server order as soon as its page arrives. Unless opts.All or
+// opts.Limit is set only the start page is fetched.
+//
+// With opts.Concurrency above 1 the first page is fetched to learn the total
+// and the remaining pages are fetched by a bounded pool of workers; items are
+// still emitted page by page in order, and emit is never called concurrently.
+func Paginate[T any](ctx context.Context, opts PageOptions, fetch PageFunc[T], emit func(T) error) error {
+	p := &paginator[T]{opts: opts, emit: emit}
+	for page := opts.StartPage; ; page++ {
+		items, total, err := fetch(ctx, page)
+		if err != nil {
+			return err
+		}
+
+		done, err := p.page(pageResult[T]{page: page, items: items, total: total})
+		if err != nil || done {
+			return err
+		}
+
+		// the total is known after the first page, fetch the rest in parallel
+		if opts.Concurrency > 1 && total > 0 && opts.PerPage > 0 {
+			return p.parallel(ctx, fetch, page+1, p.finalPage(total))
+		}
+	}
+}
+
+// pageResult is a fetched page or the error fetching it
+type pageResult[T any] struct {
+	page  int
+	items []T
+	total int
+	err   error
+}
+
+// paginator emits the items of fetched pages and decides when to stop
+type paginator[T any] struct {
+	opts PageOptions
+	emit func(T) error
+	seen int
+}
+
+// page emits the items of res and reports whether pagination is done
+func (p *paginator[T]) page(res pageResult[T]) (bool, error) {
+	stop := false
+	for _, item := range res.items {
+		if p.opts.Limit > 0 && p.seen >= p.opts.Limit {
+			break
+		}
+		err := p.emit(item)
+		switch {
+		case errors.Is(err, ErrSkip):
+			continue
+		case errors.Is(err, ErrStop):
+			stop = true
+		case err != nil:
+			return true, err
+		}
+		if stop {
+			break
+		}
+		p.seen++
+	}
+
+	if p.opts.AfterPage != nil {
+		if err := p.opts.AfterPage(res.page, res.total); err != nil {
+			return true, err
+		}
+	}
+
+	if stop || !p.opts.Multipage() || lastPage(p.opts, res.page, len(res.items), res.total) {
+		return true, nil
+	}
+	return p.opts.Limit > 0 && p.seen >= p.opts.Limit, nil
+}
+
+// parallel emits pages first to last fetched by a pool of workers
+func (p *paginator[T]) parallel(ctx context.Context, fetch PageFunc[T], first, last int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for res := range parallelPages(ctx, fetch, first, last, p.opts.Concurrency) {
+		if res.err != nil {
+			return res.err
+		}
+		done, err := p.page(res)
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalPage returns the last page worth fetching for the given total. When
+// items may be skipped the pages holding Limit items cannot be known, and
+// pages are fetched until Limit items were emitted or the total is reached.
+func (p *paginator[T]) finalPage(total int) int {
+	last := (total+p.opts.PerPage-1)/p.opts.PerPage - 1
+	if p.opts.Limit > 0 && !p.opts.Filtered {
+		if byLimit := p.opts.StartPage + (p.opts.Limit+p.opts.PerPage-1)/p.opts.PerPage - 1; byLimit < last {
+			last = byLimit
+		}
+	}
+	return last
+}
+
+// parallelPages fetches pages first to last with at most n requests in
+// flight and delivers them in page order. At most n pages are fetched ahead
+// of the consumer.
+func parallelPages[T any](ctx context.Context, fetch PageFunc[T], first, last, n int) <-chan pageResult[T] {
+	queue := make(chan chan pageResult[T], n-1)
+	go func() {
+		defer close(queue)
+		for page := first; page <= last; page++ {
+			ch := make(chan pageResult[T], 1)
+			select {
+			case queue <- ch:
+			case <-ctx.Done():
+				return
+			}
+			go func(page int) {
+				items, total, err := fetch(ctx, page)
+				ch <- pageResult[T]{page: page, items: items, total: total, err: err}
+			}(page)
+		}
+	}()
+
+	out := make(chan pageResult[T])
+	go func() {
+		defer close(out)
+		for ch := range queue {
+			select {
+			case out <- <-ch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// lastPage reports whether page is the final page of the result set
+func lastPage(opts PageOptions, page, items, total int) bool {
+	if items == 0 {
+		return true
+	}
+	if opts.PerPage > 0 {
+		if items < opts.PerPage {
+			return true
+		}
+		if total > 0 && (page+1)*opts.PerPage >= total {
+			return true
+		}
+	}
+	return false
+}
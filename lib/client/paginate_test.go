@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pager serves total numbered items in pages of perPage, answering later
+// pages faster than earlier ones so parallel fetches complete out of order
+type pager struct {
+	total   int
+	perPage int
+
+	mu       sync.Mutex
+	fetched  []int
+	inFlight int32
+	peak     int32
+}
+
+func (p *pager) fetch(ctx context.Context, page int) ([]int, int, error) {
+	n := atomic.AddInt32(&p.inFlight, 1)
+	defer atomic.AddInt32(&p.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&p.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&p.peak, peak, n) {
+			break
+		}
+	}
+
+	p.mu.Lock()
+	p.fetched = append(p.fetched, page)
+	p.mu.Unlock()
+
+	pages := (p.total + p.perPage - 1) / p.perPage
+	select {
+	case <-time.After(time.Duration(pages-page) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+
+	var items []int
+	for i := page * p.perPage; i < (page+1)*p.perPage && i < p.total; i++ {
+		items = append(items, i)
+	}
+	return items, p.total, nil
+}
+
+func (p *pager) pagesFetched() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.fetched)
+}
+
+func collect(t *testing.T, p *pager, opts PageOptions, emit func(int) error) []int {
+	t.Helper()
+	var got []int
+	err := Paginate(context.Background(), opts, p.fetch, func(i int) error {
+		if emit != nil {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+		got = append(got, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Paginate: %v", err)
+	}
+	return got
+}
+
+func wantSequence(t *testing.T, got []int, from, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("got %d items, want %d", len(got), n)
+	}
+	for i, v := range got {
+		if v != from+i {
+			t.Fatalf("item %d = %d, want %d (items out of order: %v)", i, v, from+i, got)
+		}
+	}
+}
+
+func TestPaginateStartPageOnly(t *testing.T) {
+	p := &pager{total: 100, perPage: 10}
+	got := collect(t, p, PageOptions{StartPage: 2, PerPage: 10}, nil)
+	wantSequence(t, got, 20, 10)
+	if n := p.pagesFetched(); n != 1 {
+		t.Errorf("fetched %d pages, want 1", n)
+	}
+}
+
+func TestPaginateParallelKeepsOrder(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 2, 4, 16} {
+		p := &pager{total: 95, perPage: 10}
+		got := collect(t, p, PageOptions{PerPage: 10, All: true, Concurrency: concurrency}, nil)
+		wantSequence(t, got, 0, 95)
+		if n := p.pagesFetched(); n != 10 {
+			t.Errorf("concurrency %d: fetched %d pages, want 10", concurrency, n)
+		}
+	}
+}
+
+func TestPaginateParallelBoundsRequests(t *testing.T) {
+	p := &pager{total: 200, perPage: 5}
+	collect(t, p, PageOptions{PerPage: 5, All: true, Concurrency: 3}, nil)
+	if peak := atomic.LoadInt32(&p.peak); peak > 3 {
+		t.Errorf("%d requests in flight, want at most 3", peak)
+	}
+}
+
+func TestPaginateLimit(t *testing.T) {
+	p := &pager{total: 100, perPage: 10}
+	got := collect(t, p, PageOptions{StartPage: 1, PerPage: 10, Limit: 25, Concurrency: 4}, nil)
+	wantSequence(t, got, 10, 25)
+	if n := p.pagesFetched(); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestPaginateFilteredLimitFetchesPastLimitPages(t *testing.T) {
+	p := &pager{total: 100, perPage: 10}
+	odd := func(i int) error {
+		if i%2 == 0 {
+			return ErrSkip
+		}
+		return nil
+	}
+	got := collect(t, p, PageOptions{PerPage: 10, Limit: 20, Filtered: true, Concurrency: 3}, odd)
+	if len(got) != 20 || got[0] != 1 || got[19] != 39 {
+		t.Errorf("got %v, want the odd items 1 to 39", got)
+	}
+}
+
+func TestPaginateStop(t *testing.T) {
+	p := &pager{total: 100, perPage: 10}
+	stop := func(i int) error {
+		if i == 42 {
+			return ErrStop
+		}
+		return nil
+	}
+	got := collect(t, p, PageOptions{PerPage: 10, All: true, Concurrency: 4}, stop)
+	wantSequence(t, got, 0, 42)
+}
+
+func TestPaginateAfterPage(t *testing.T) {
+	p := &pager{total: 30, perPage: 10}
+	var pages []int
+	opts := PageOptions{PerPage: 10, All: true, Concurrency: 2, AfterPage: func(page, total int) error {
+		if total != 30 {
+			t.Errorf("AfterPage total = %d, want 30", total)
+		}
+		pages = append(pages, page)
+		return nil
+	}}
+	collect(t, p, opts, nil)
+	if len(pages) != 3 || pages[0] != 0 || pages[1] != 1 || pages[2] != 2 {
+		t.Errorf("AfterPage pages = %v, want [0 1 2]", pages)
+	}
+}
+
+func TestPaginateFetchError(t *testing.T) {
+	failure := errors.New("page 3 failed")
+	p := &pager{total: 100, perPage: 10}
+	fetch := func(ctx context.Context, page int) ([]int, int, error) {
+		if page == 3 {
+			return nil, 0, failure
+		}
+		return p.fetch(ctx, page)
+	}
+
+	var got []int
+	err := Paginate(context.Background(), PageOptions{PerPage: 10, All: true, Concurrency: 4}, fetch, func(i int) error {
+		got = append(got, i)
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+	wantSequence(t, got, 0, 30)
+}
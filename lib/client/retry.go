@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled on every attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed exponential backoff
+	MaxBackoff time.Duration
+	// Jitter randomises each delay by up to this fraction (0..1) in either direction
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used by clients created with New
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Jitter:         0.2,
+}
+
+// NoRetry performs every request exactly once
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Backoff returns the delay before retry number attempt (starting at 1)
+// without jitter or server hints applied
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	d := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// delay returns how long to wait before retry number attempt. Delays
+// requested by the server through Retry-After or rate-limit headers take
+// precedence over the computed backoff when they are longer.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.Backoff(attempt)
+	if p.Jitter > 0 && d > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d = time.Duration(float64(d) * (1 + j*(2*rand.Float64()-1)))
+	}
+
+	if apiErr, ok := AsAPIError(err); ok {
+		if apiErr.RetryAfter > d {
+			d = apiErr.RetryAfter
+		}
+	}
+	return d
+}
+
+// shouldRetry reports whether a request using method that failed with err
+// may be repeated. Non-idempotent requests are only repeated when the server
+// explicitly rejected them without processing.
+func shouldRetry(method string, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if apiErr, ok := AsAPIError(err); ok {
+		if !apiErr.Retryable {
+			return false
+		}
+		return idempotent(method) || apiErr.IsRateLimited() || apiErr.StatusCode == http.StatusServiceUnavailable
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return idempotent(method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry retries quickly so tests don't wait on the default backoff
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// failingServer answers the first failures requests with status and the
+// rest with an empty JSON object, counting every request it receives
+func failingServer(t *testing.T, failures int, status int, header http.Header) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&calls, 1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testClient(srv *httptest.Server, policy RetryPolicy) *Client {
+	c := New(srv.URL, "key")
+	c.Retry = policy
+	return c
+}
+
+func TestRetryTooManyRequestsHonorsRetryAfter(t *testing.T) {
+	srv, calls := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	c := testClient(srv, fastRetry)
+	var waits []time.Duration
+	c.OnRetry = func(attempt int, wait time.Duration, err error) {
+		waits = append(waits, wait)
+	}
+
+	start := time.Now()
+	if err := c.Get(context.Background(), "/", nil, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if len(waits) != 1 || waits[0] < time.Second {
+		t.Errorf("retry waits = %v, want one wait of at least 1s", waits)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before Retry-After passed", elapsed)
+	}
+}
+
+func TestRetryServiceUnavailable(t *testing.T) {
+	srv, calls := failingServer(t, 2, http.StatusServiceUnavailable, nil)
+	if err := testClient(srv, fastRetry).Get(context.Background(), "/", nil, nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestRetryStopsAtMaxAttempts(t *testing.T) {
+	srv, calls := failingServer(t, 10, http.StatusBadGateway, nil)
+	err := testClient(srv, fastRetry).Get(context.Background(), "/", nil, nil)
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("err = %v, want 502 APIError", err)
+	}
+	if got := atomic.LoadInt32(calls); got != int32(fastRetry.MaxAttempts) {
+		t.Errorf("requests = %d, want %d", got, fastRetry.MaxAttempts)
+	}
+}
+
+func TestNoRetryPerformsOneAttempt(t *testing.T) {
+	srv, calls := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+	if err := testClient(srv, NoRetry).Get(context.Background(), "/", nil, nil); err == nil {
+		t.Fatal("Get succeeded, want error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryPostOnlyWhenRejected(t *testing.T) {
+	tests := []struct {
+		status int
+		want   int32
+	}{
+		// the server may have processed the request, repeating it could
+		// register a contract twice
+		{http.StatusInternalServerError, 1},
+		{http.StatusBadGateway, 1},
+		// rejected without processing
+		{http.StatusServiceUnavailable, 2},
+		{http.StatusTooManyRequests, 2},
+	}
+	for _, tt := range tests {
+		srv, calls := failingServer(t, 1, tt.status, nil)
+		c := testClient(srv, fastRetry)
+		c.do(context.Background(), http.MethodPost, "/", nil, map[string]string{"a": "b"}, nil)
+		if got := atomic.LoadInt32(calls); got != tt.want {
+			t.Errorf("POST failing with %d: requests = %d, want %d", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRetryNotFoundIsNotRetried(t *testing.T) {
+	srv, calls := failingServer(t, 10, http.StatusNotFound, nil)
+	testClient(srv, fastRetry).Get(context.Background(), "/", nil, nil)
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, w := range want {
+		if got := p.Backoff(attempt); got != w {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, w)
+		}
+	}
+}
+
+func TestDelayJitterBounds(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.2}
+	lo, hi := 800*time.Millisecond, 1200*time.Millisecond
+	varied := false
+	for i := 0; i < 1000; i++ {
+		d := p.delay(1, nil)
+		if d < lo || d > hi {
+			t.Fatalf("delay = %s, want within [%s, %s]", d, lo, hi)
+		}
+		varied = varied || d != time.Second
+	}
+	if !varied {
+		t.Error("delay never varied with jitter set")
+	}
+
+	// jitter above 1 is capped so the delay never turns negative
+	p.Jitter = 5
+	for i := 0; i < 1000; i++ {
+		if d := p.delay(1, nil); d < 0 || d > 2*time.Second {
+			t.Fatalf("delay with jitter 5 = %s, want within [0, 2s]", d)
+		}
+	}
+}
+
+func TestDelayPrefersLongerRetryAfter(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second}
+	if got := p.delay(1, &APIError{RetryAfter: 5 * time.Second}); got != 5*time.Second {
+		t.Errorf("delay = %s, want Retry-After 5s", got)
+	}
+	if got := p.delay(1, &APIError{RetryAfter: time.Millisecond}); got != time.Second {
+		t.Errorf("delay = %s, want backoff 1s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-10 * time.Second).Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
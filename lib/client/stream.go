@@ -0,0 +1,377 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamURL returns the websocket URL for path on the API server
+func (c *Client) StreamURL(path string, query url.Values) string {
+	u := c.BaseURL
+	switch {
+	case strings.HasPrefix(u, "https://"):
+		u = "wss://" + strings.TrimPrefix(u, "https://")
+	case strings.HasPrefix(u, "http://"):
+		u = "ws://" + strings.TrimPrefix(u, "http://")
+	}
+
+	u += path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// DialStream opens a websocket connection to path. The API key is sent as a
+// bearer token header when StreamHeaderAuth is set and as the api_key query
+// parameter otherwise. Handshake failures are returned as *APIError.
+func (c *Client) DialStream(ctx context.Context, path string, query url.Values) (*websocket.Conn, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	if c.StreamHeaderAuth {
+		headers.Set("Authorization", "Bearer "+c.APIKey)
+	} else {
+		q.Set("api_key", c.APIKey)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, c.StreamURL(path, q), headers)
+	if err != nil {
+		if resp != nil {
+			return nil, ErrorFromResponse(resp)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Stream keepalive defaults
+const (
+	DefaultPingInterval = 30 * time.Second
+	DefaultReadTimeout  = 90 * time.Second
+
+	// closeGracePeriod is how long to wait for the server to acknowledge a
+	// close frame
+	closeGracePeriod = time.Second
+)
+
+// StreamOptions controls a subscription started with Subscribe
+type StreamOptions struct {
+	// Path and Query identify the stream
+	Path  string
+	Query url.Values
+	// Name identifies the stream in reconnect notifications, defaults to Path
+	Name string
+	// Resume, if set, returns query parameters added when reconnecting so the
+	// server resumes after the last message handled
+	Resume func() url.Values
+	// MaxReconnects bounds consecutive failed reconnects, 0 means unlimited
+	MaxReconnects int
+	// PingInterval is the interval between keepalive pings
+	PingInterval time.Duration
+	// ReadTimeout drops the connection when neither a message nor a pong
+	// arrives within this duration
+	ReadTimeout time.Duration
+	// Stats, if set, is updated while the subscription runs
+	Stats *StreamStats
+}
+
+// StreamStats summarises a subscription
+type StreamStats struct {
+	Started    time.Time
+	Messages   int
+	Reconnects int
+	// Invalid counts messages the handler skipped as malformed
+	Invalid int
+}
+
+// Duration returns how long the subscription has been running
+func (s *StreamStats) Duration() time.Duration {
+	return time.Since(s.Started).Round(time.Millisecond)
+}
+
+// Summary describes the subscription in a single line, eg. "received 12
+// transfers in 3.2s, 1 reconnects, last position 21809092"
+func (s *StreamStats) Summary(kind string, cursor *Cursor) string {
+	summary := fmt.Sprintf("received %d %s in %s", s.Messages, kind, s.Duration())
+	if s.Reconnects > 0 {
+		summary += fmt.Sprintf(", %d reconnects", s.Reconnects)
+	}
+	if s.Invalid > 0 {
+		summary += fmt.Sprintf(", %d invalid skipped", s.Invalid)
+	}
+	if cursor != nil && cursor.Skipped > 0 {
+		summary += fmt.Sprintf(", %d duplicates skipped", cursor.Skipped)
+	}
+	if cursor != nil && cursor.Started() {
+		summary += fmt.Sprintf(", last position %d", cursor.Position)
+	}
+	return summary
+}
+
+// Subscribe reads messages from a stream and passes them to handle. When the
+// connection drops it reconnects with the client's retry backoff, resuming
+// from opts.Resume. When ctx is done a close frame is sent and ctx.Err() is
+// returned. Otherwise it returns when handle fails, the reconnect limit is
+// reached or the server rejects the handshake with a non-retryable status.
+func (c *Client) Subscribe(ctx context.Context, opts StreamOptions, handle func(message []byte) error) error {
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = DefaultPingInterval
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = DefaultReadTimeout
+	}
+	if opts.Name == "" {
+		opts.Name = opts.Path
+	}
+
+	stats := opts.Stats
+	if stats == nil {
+		stats = &StreamStats{}
+	}
+	stats.Started = time.Now()
+
+	failures := 0
+	for reconnect := false; ; reconnect = true {
+		query := opts.Query
+		if reconnect && opts.Resume != nil {
+			query = url.Values{}
+			for k, v := range opts.Query {
+				query[k] = v
+			}
+			for k, v := range opts.Resume() {
+				query[k] = v
+			}
+		}
+
+		conn, err := c.DialStream(ctx, opts.Path, query)
+		if err == nil {
+			if reconnect {
+				stats.Reconnects++
+				if c.OnReconnect != nil {
+					c.OnReconnect(opts.Name, failures, 0, nil)
+				}
+			}
+			var delivered bool
+			delivered, err = c.readStream(ctx, conn, opts, stats, handle)
+			if delivered {
+				failures = 0
+			}
+			var herr handlerError
+			if errors.As(err, &herr) {
+				return herr.err
+			}
+		} else if apiErr, ok := AsAPIError(err); ok && !apiErr.Retryable {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		failures++
+		if opts.MaxReconnects > 0 && failures > opts.MaxReconnects {
+			return fmt.Errorf("giving up after %d reconnect attempts: %w", opts.MaxReconnects, err)
+		}
+
+		wait := c.Retry.delay(failures, err)
+		if c.OnReconnect != nil {
+			c.OnReconnect(opts.Name, failures, wait, err)
+		}
+		if serr := sleep(ctx, wait); serr != nil {
+			return serr
+		}
+	}
+}
+
+// SubscribeAll runs one subscription per entry of streams concurrently, each
+// with its own reconnect state, and passes their messages to handle along
+// with the index of the stream. Calls to handle are serialised in arrival
+// order. When one subscription fails the others are stopped.
+func (c *Client) SubscribeAll(ctx context.Context, streams []StreamOptions, handle func(i int, message []byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	errs := make([]error, len(streams))
+
+	var wg sync.WaitGroup
+	for i, opts := range streams {
+		wg.Add(1)
+		go func(i int, opts StreamOptions) {
+			defer wg.Done()
+			errs[i] = c.Subscribe(ctx, opts, func(message []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				return handle(i, message)
+			})
+			if errs[i] != nil && ctx.Err() == nil {
+				cancel()
+			}
+		}(i, opts)
+	}
+	wg.Wait()
+
+	// report the failure that stopped the others rather than their
+	// cancellation
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handlerError wraps an error returned by a Subscribe handler so it is not
+// mistaken for a connection failure
+type handlerError struct {
+	err error
+}
+
+func (e handlerError) Error() string {
+	return e.err.Error()
+}
+
+// readStream reads messages from conn until it fails, keeping the connection
+// alive with pings. It reports whether any message was handled.
+func (c *Client) readStream(ctx context.Context, conn *websocket.Conn, opts StreamOptions, stats *StreamStats, handle func([]byte) error) (bool, error) {
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	conn.SetReadDeadline(time.Now().Add(opts.ReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(opts.ReadTimeout))
+	})
+
+	go func() {
+		ticker := time.NewTicker(opts.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				// say goodbye properly, then give the server a moment to
+				// answer before unblocking ReadMessage
+				msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				select {
+				case <-done:
+				case <-time.After(closeGracePeriod):
+					conn.Close()
+				}
+				return
+			case <-ticker.C:
+				deadline := time.Now().Add(opts.PingInterval)
+				if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	delivered := false
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return delivered, err
+		}
+		if ctx.Err() != nil {
+			// draining until the server acknowledges the close frame
+			continue
+		}
+		conn.SetReadDeadline(time.Now().Add(opts.ReadTimeout))
+		stats.Messages++
+
+		if err := handle(message); err != nil {
+			return delivered, handlerError{err}
+		}
+		delivered = true
+	}
+}
+
+// Cursor tracks the position of the last message handled on a stream so
+// messages replayed after resuming from that position are skipped. Several
+// messages may share a position, so they are told apart by key. Messages
+// are only skipped while a resumed stream replays the resume position, a
+// live stream delivers every message.
+type Cursor struct {
+	Position uint64
+	// Skipped counts replayed messages that were skipped
+	Skipped int
+	started bool
+	// seen counts the messages handled at Position by key
+	seen map[string]int
+	// replay counts the messages at Position still expected to be replayed,
+	// nil unless the stream was resumed
+	replay map[string]int
+}
+
+// Resume returns the position to resume the stream from and expects the
+// messages handled at it to be replayed. It reports false before any
+// message was handled.
+func (c *Cursor) Resume() (uint64, bool) {
+	if !c.started {
+		return 0, false
+	}
+	c.replay = make(map[string]int, len(c.seen))
+	for k, n := range c.seen {
+		c.replay[k] = n
+	}
+	return c.Position, true
+}
+
+// Next reports whether the message at pos identified by key has not been
+// handled yet and records it as handled.
+func (c *Cursor) Next(pos uint64, key string) bool {
+	if c.replay != nil {
+		switch {
+		case pos < c.Position:
+			c.Skipped++
+			return false
+		case pos == c.Position && c.replay[key] > 0:
+			c.replay[key]--
+			c.Skipped++
+			return false
+		case pos > c.Position:
+			// past the resume position, the replay is over
+			c.replay = nil
+		}
+	}
+
+	switch {
+	case !c.started || pos > c.Position:
+		c.started = true
+		c.Position = pos
+		c.seen = map[string]int{}
+	case pos < c.Position:
+		// out of order, resuming from Position still covers the newest
+		return true
+	}
+	c.seen[key]++
+	return true
+}
+
+// Started reports whether any message has been handled
+func (c *Cursor) Started() bool {
+	return c.started
+}
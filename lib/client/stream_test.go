@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCursorLiveStreamKeepsEveryMessage(t *testing.T) {
+	var c Cursor
+	if c.Started() {
+		t.Fatal("new cursor started")
+	}
+	if _, ok := c.Resume(); ok {
+		t.Fatal("Resume before any message reported a position")
+	}
+
+	// several logs of one transaction without a log index may share a key,
+	// a live stream must deliver them all
+	for _, m := range []struct {
+		pos uint64
+		key string
+	}{{10, "a"}, {10, "a"}, {10, "b"}, {11, "c"}, {9, "late"}, {11, "c"}} {
+		if !c.Next(m.pos, m.key) {
+			t.Errorf("Next(%d, %q) skipped a live message", m.pos, m.key)
+		}
+	}
+	if c.Position != 11 || c.Skipped != 0 {
+		t.Errorf("Position = %d, Skipped = %d, want 11 and 0", c.Position, c.Skipped)
+	}
+}
+
+func TestCursorSkipsReplayAfterResume(t *testing.T) {
+	var c Cursor
+	c.Next(5, "x")
+	c.Next(7, "a")
+	c.Next(7, "a")
+	c.Next(7, "b")
+
+	pos, ok := c.Resume()
+	if !ok || pos != 7 {
+		t.Fatalf("Resume = %d, %v, want 7", pos, ok)
+	}
+
+	replay := []struct {
+		pos  uint64
+		key  string
+		want bool
+	}{
+		{5, "x", false}, // before the resume position
+		{7, "a", false},
+		{7, "b", false},
+		{7, "a", false}, // both copies were handled
+		{7, "c", true},  // new at the resume position
+		{8, "d", true},
+		{8, "d", true}, // past the replay, duplicates are live messages
+		{7, "a", true},
+	}
+	for _, m := range replay {
+		if got := c.Next(m.pos, m.key); got != m.want {
+			t.Errorf("Next(%d, %q) = %v, want %v", m.pos, m.key, got, m.want)
+		}
+	}
+	if c.Skipped != 4 {
+		t.Errorf("Skipped = %d, want 4", c.Skipped)
+	}
+	if c.Position != 8 {
+		t.Errorf("Position = %d, want 8", c.Position)
+	}
+}
+
+func TestCursorResumeTwice(t *testing.T) {
+	var c Cursor
+	c.Next(3, "a")
+	c.Resume()
+	// the connection dropped again before anything was replayed
+	c.Resume()
+	if c.Next(3, "a") {
+		t.Error("replayed message handled after a second resume")
+	}
+	if !c.Next(3, "b") {
+		t.Error("new message at the resume position skipped")
+	}
+}
+
+// streamServer serves a websocket stream. Each connection gets the next
+// batch of messages and is then closed, the resume query of every
+// connection is recorded.
+type streamServer struct {
+	batches [][]string
+
+	mu      sync.Mutex
+	queries []url.Values
+}
+
+func (s *streamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	n := len(s.queries)
+	s.queries = append(s.queries, r.URL.Query())
+	s.mu.Unlock()
+
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if n >= len(s.batches) {
+		// keep the last connection open until the client is done
+		conn.ReadMessage()
+		return
+	}
+	for _, m := range s.batches[n] {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+			return
+		}
+	}
+}
+
+var errDone = errors.New("done")
+
+func TestSubscribeReconnectsAndResumes(t *testing.T) {
+	srv := &streamServer{batches: [][]string{
+		{"1:a", "2:b", "2:c"},
+		// the server replays from the resume position
+		{"2:b", "2:c", "2:d", "3:e"},
+	}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := New(ts.URL, "key")
+	c.Retry = fastRetry
+	var reconnects []error
+	c.OnReconnect = func(name string, attempt int, wait time.Duration, err error) {
+		reconnects = append(reconnects, err)
+	}
+
+	var cursor Cursor
+	var stats StreamStats
+	var got []string
+	opts := StreamOptions{
+		Path:  "/stream",
+		Query: url.Values{"q": {"1"}},
+		Stats: &stats,
+		Resume: func() url.Values {
+			pos, ok := cursor.Resume()
+			if !ok {
+				return nil
+			}
+			return url.Values{"from": {strconv.FormatUint(pos, 10)}}
+		},
+	}
+	err := c.Subscribe(context.Background(), opts, func(message []byte) error {
+		pos, key, _ := strings.Cut(string(message), ":")
+		n, _ := strconv.ParseUint(pos, 10, 64)
+		if cursor.Next(n, key) {
+			got = append(got, key)
+		}
+		if key == "e" {
+			return errDone
+		}
+		return nil
+	})
+	if !errors.Is(err, errDone) {
+		t.Fatalf("Subscribe = %v, want the handler's error", err)
+	}
+
+	if want := "a b c d e"; strings.Join(got, " ") != want {
+		t.Errorf("handled %v, want %s", got, want)
+	}
+	if len(srv.queries) != 2 {
+		t.Fatalf("%d connections, want 2", len(srv.queries))
+	}
+	if q := srv.queries[0]; q.Get("q") != "1" || q.Has("from") {
+		t.Errorf("first query = %v, want no resume position", q)
+	}
+	if q := srv.queries[1]; q.Get("q") != "1" || q.Get("from") != "2" {
+		t.Errorf("resume query = %v, want q=1 and from=2", q)
+	}
+	if stats.Messages != 7 || stats.Reconnects != 1 || cursor.Skipped != 2 {
+		t.Errorf("stats = %+v, skipped %d, want 7 messages, 1 reconnect and 2 skipped", stats, cursor.Skipped)
+	}
+	if len(reconnects) == 0 {
+		t.Error("OnReconnect not called")
+	}
+}
+
+func TestSubscribeGivesUpAfterMaxReconnects(t *testing.T) {
+	var dials int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dials++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, "key")
+	c.Retry = fastRetry
+	err := c.Subscribe(context.Background(), StreamOptions{Path: "/stream", MaxReconnects: 2}, func([]byte) error {
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "giving up after 2 reconnect attempts") {
+		t.Errorf("Subscribe = %v, want giving up", err)
+	}
+	if dials != 3 {
+		t.Errorf("%d dials, want 3", dials)
+	}
+}
+
+func TestSubscribeStopsOnPermanentHandshakeError(t *testing.T) {
+	var dials int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dials++
+		http.Error(w, `{"error": "invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, "key")
+	c.Retry = fastRetry
+	err := c.Subscribe(context.Background(), StreamOptions{Path: "/stream"}, func([]byte) error {
+		return nil
+	})
+	apiErr, ok := AsAPIError(err)
+	if !ok || !apiErr.IsUnauthorized() || apiErr.Message != "invalid api key" {
+		t.Errorf("Subscribe = %v, want the 401 APIError", err)
+	}
+	if dials != 1 {
+		t.Errorf("%d dials, want 1", dials)
+	}
+}
@@ -0,0 +1,233 @@
+// Package decode types contract event arguments using the contract ABI
+package decode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+)
+
+// ABI converts event arguments to the types declared by a contract ABI
+type ABI struct {
+	ABI abi.ABI
+	// events by name, overloaded events share a name
+	events map[string][]abi.Event
+}
+
+// ParseABI parses a contract ABI in JSON, given as an ABI array or a
+// Foundry or Hardhat artifact
+func ParseABI(b []byte) (*ABI, error) {
+	raw, err := artifact.ABI(b)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI: %w", err)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ABI: %w", err)
+	}
+
+	a := &ABI{ABI: parsed, events: map[string][]abi.Event{}}
+	for _, e := range parsed.Events {
+		a.events[e.RawName] = append(a.events[e.RawName], e)
+	}
+	return a, nil
+}
+
+// LoadABI reads a contract ABI from a JSON ABI or artifact file
+func LoadABI(path string) (*ABI, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ABI file: %w", err)
+	}
+	return ParseABI(b)
+}
+
+// Events returns the events of the ABI ordered by name and signature
+func (a *ABI) Events() []abi.Event {
+	events := make([]abi.Event, 0, len(a.ABI.Events))
+	for _, e := range a.ABI.Events {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].RawName != events[j].RawName {
+			return events[i].RawName < events[j].RawName
+		}
+		return events[i].Sig < events[j].Sig
+	})
+	return events
+}
+
+// EventNames returns the names of the events of the ABI in order, overloaded
+// events are named once
+func (a *ABI) EventNames() []string {
+	var names []string
+	for _, e := range a.Events() {
+		if len(names) == 0 || names[len(names)-1] != e.RawName {
+			names = append(names, e.RawName)
+		}
+	}
+	return names
+}
+
+// HasEvent reports whether the ABI has an event called name
+func (a *ABI) HasEvent(name string) bool {
+	return len(a.events[name]) > 0
+}
+
+// Args converts the arguments of the event called name in place: addresses
+// become checksummed hex, bytes become hex and integers become exact
+// *big.Int. Events missing from the ABI and values that do not fit their type
+// are left unchanged.
+func (a *ABI) Args(name string, args map[string]interface{}) {
+	e, ok := a.lookup(name, args)
+	if !ok {
+		return
+	}
+	for _, arg := range e.Inputs {
+		if v, ok := args[arg.Name]; ok && arg.Name != "" {
+			args[arg.Name] = Value(arg.Type, v)
+		}
+	}
+}
+
+// lookup finds the event called name, preferring the overload whose
+// arguments are all present in args
+func (a *ABI) lookup(name string, args map[string]interface{}) (abi.Event, bool) {
+	candidates := a.events[name]
+	if len(candidates) == 0 {
+		return abi.Event{}, false
+	}
+	for _, e := range candidates {
+		complete := true
+		for _, arg := range e.Inputs {
+			if _, ok := args[arg.Name]; !ok {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return e, true
+		}
+	}
+	return candidates[0], true
+}
+
+// Value converts a JSON decoded value to the value rendered for ABI type t,
+// returning v unchanged when it cannot be converted exactly
+func Value(t abi.Type, v interface{}) interface{} {
+	switch t.T {
+	case abi.AddressTy:
+		if s, ok := v.(string); ok && common.IsHexAddress(s) {
+			return common.HexToAddress(s).Hex()
+		}
+		if n, ok := toBigInt(v); ok && n.Sign() >= 0 && n.BitLen() <= 160 {
+			return common.BigToAddress(n).Hex()
+		}
+	case abi.IntTy, abi.UintTy:
+		if n, ok := toBigInt(v); ok {
+			return n
+		}
+	case abi.FixedBytesTy, abi.HashTy:
+		size := t.Size
+		if t.T == abi.HashTy {
+			size = common.HashLength
+		}
+		if b, ok := toBytes(v); ok {
+			return hexutil.Encode(b)
+		}
+		if n, ok := toBigInt(v); ok && n.Sign() >= 0 && len(n.Bytes()) <= size {
+			return hexutil.Encode(common.LeftPadBytes(n.Bytes(), size))
+		}
+	case abi.BytesTy:
+		if b, ok := toBytes(v); ok {
+			return hexutil.Encode(b)
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		if list, ok := v.([]interface{}); ok {
+			out := make([]interface{}, len(list))
+			for i, e := range list {
+				out[i] = Value(*t.Elem, e)
+			}
+			return out
+		}
+	case abi.TupleTy:
+		switch fields := v.(type) {
+		case map[string]interface{}:
+			out := make(map[string]interface{}, len(fields))
+			for k, e := range fields {
+				out[k] = e
+			}
+			for i, name := range t.TupleRawNames {
+				if e, ok := fields[name]; ok {
+					out[name] = Value(*t.TupleElems[i], e)
+				}
+			}
+			return out
+		case []interface{}:
+			if len(fields) == len(t.TupleElems) {
+				out := make([]interface{}, len(fields))
+				for i, e := range fields {
+					out[i] = Value(*t.TupleElems[i], e)
+				}
+				return out
+			}
+		}
+	}
+	return v
+}
+
+// toBigInt converts integer values without losing precision. Floats are only
+// accepted when they hold an exact integer.
+func toBigInt(v interface{}) (*big.Int, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		return n, n != nil
+	case json.Number:
+		return new(big.Int).SetString(n.String(), 10)
+	case string:
+		if strings.HasPrefix(n, "0x") || strings.HasPrefix(n, "0X") {
+			return new(big.Int).SetString(n[2:], 16)
+		}
+		return new(big.Int).SetString(n, 10)
+	case float64:
+		if n == float64(int64(n)) && n < 1<<53 && n > -(1<<53) {
+			return big.NewInt(int64(n)), true
+		}
+	}
+	return nil, false
+}
+
+// toBytes converts hex strings, base64 strings and arrays of byte values, the
+// encodings of []byte and [N]byte in JSON
+func toBytes(v interface{}) ([]byte, bool) {
+	switch b := v.(type) {
+	case string:
+		if strings.HasPrefix(b, "0x") || strings.HasPrefix(b, "0X") {
+			decoded, err := hexutil.Decode(b)
+			return decoded, err == nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(b)
+		return decoded, err == nil
+	case []interface{}:
+		out := make([]byte, len(b))
+		for i, e := range b {
+			n, ok := toBigInt(e)
+			if !ok || !n.IsUint64() || n.Uint64() > 255 {
+				return nil, false
+			}
+			out[i] = byte(n.Uint64())
+		}
+		return out, true
+	}
+	return nil, false
+}
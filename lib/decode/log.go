@@ -0,0 +1,151 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Log is a raw event log as returned by eth_getLogs and in transaction
+// receipts. Only the topics and data are needed to decode it.
+type Log struct {
+	Address     *common.Address `json:"address"`
+	Topics      []common.Hash   `json:"topics"`
+	Data        hexutil.Bytes   `json:"data"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   *common.Hash    `json:"blockHash"`
+	TxHash      *common.Hash    `json:"transactionHash"`
+	TxIndex     *hexutil.Uint64 `json:"transactionIndex"`
+	Index       *hexutil.Uint64 `json:"logIndex"`
+}
+
+// ReadLogs reads logs from r: a single log, an array of logs, a receipt
+// with a logs field or a JSON-RPC response with any of these as result
+func ReadLogs(r io.Reader) ([]Log, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseLogs(bytes.TrimSpace(b))
+}
+
+func parseLogs(b []byte) ([]Log, error) {
+	if len(b) > 0 && b[0] == '[' {
+		var logs []Log
+		if err := json.Unmarshal(b, &logs); err != nil {
+			return nil, fmt.Errorf("invalid logs: %w", err)
+		}
+		return logs, nil
+	}
+
+	var wrapper struct {
+		Result json.RawMessage `json:"result"`
+		Logs   json.RawMessage `json:"logs"`
+	}
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid log: %w", err)
+	}
+	switch {
+	case len(wrapper.Result) > 0:
+		return parseLogs(bytes.TrimSpace(wrapper.Result))
+	case len(wrapper.Logs) > 0:
+		return parseLogs(bytes.TrimSpace(wrapper.Logs))
+	}
+
+	var l Log
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, fmt.Errorf("invalid log: %w", err)
+	}
+	return []Log{l}, nil
+}
+
+// Log decodes the arguments of a raw log. The event is identified by the
+// first topic, so anonymous events cannot be decoded. Indexed arguments of
+// dynamic types are only stored as their hash, which is returned instead.
+func (a *ABI) Log(l Log) (*abi.Event, map[string]interface{}, error) {
+	if len(l.Topics) == 0 {
+		return nil, nil, errors.New("log has no topics, anonymous events cannot be decoded")
+	}
+	e, err := a.ABI.EventByID(l.Topics[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("no event with topic %s in ABI", l.Topics[0].Hex())
+	}
+
+	values := map[string]interface{}{}
+	if err := e.Inputs.NonIndexed().UnpackIntoMap(values, l.Data); err != nil {
+		return nil, nil, fmt.Errorf("decode %s data: %w", e.Name, err)
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range e.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(indexed) != len(l.Topics)-1 {
+		return nil, nil, fmt.Errorf("%s has %d indexed arguments but the log has %d topics", e.Name, len(indexed), len(l.Topics)-1)
+	}
+	for i, arg := range indexed {
+		topic := l.Topics[i+1]
+		switch arg.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			values[arg.Name] = topic
+		default:
+			if err := abi.ParseTopicsIntoMap(values, abi.Arguments{arg}, []common.Hash{topic}); err != nil {
+				return nil, nil, fmt.Errorf("decode %s topic %s: %w", e.Name, arg.Name, err)
+			}
+		}
+	}
+
+	args := make(map[string]interface{}, len(values))
+	for _, arg := range e.Inputs {
+		if v, ok := values[arg.Name]; ok {
+			args[arg.Name] = unpacked(arg.Type, v)
+		}
+	}
+	return e, args, nil
+}
+
+// unpacked converts a value unpacked by go-ethereum to the value rendered
+// for ABI type t, matching the values typed by Value
+func unpacked(t abi.Type, v interface{}) interface{} {
+	switch v := v.(type) {
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case *big.Int:
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8:
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && t.Elem != nil:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = unpacked(*t.Elem, rv.Index(i).Interface())
+		}
+		return out
+	case rv.Kind() == reflect.Struct && t.T == abi.TupleTy:
+		out := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField() && i < len(t.TupleRawNames); i++ {
+			out[t.TupleRawNames[i]] = unpacked(*t.TupleElems[i], rv.Field(i).Interface())
+		}
+		return out
+	}
+	return v
+}
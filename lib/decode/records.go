@@ -0,0 +1,33 @@
+package decode
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+)
+
+// EventRecord describes an ABI event as an output record: its canonical
+// signature, the topic0 hash identifying its logs and its indexed and data
+// arguments. Anonymous events have no topic0.
+func EventRecord(e abi.Event) format.Record {
+	indexed, data := []string{}, []string{}
+	for _, arg := range e.Inputs {
+		s := arg.Type.String() + " " + arg.Name
+		if arg.Indexed {
+			indexed = append(indexed, s)
+		} else {
+			data = append(data, s)
+		}
+	}
+
+	var topic0 interface{}
+	if !e.Anonymous {
+		topic0 = e.ID.Hex()
+	}
+	return format.Record{
+		{Name: "event", Value: e.RawName},
+		{Name: "signature", Value: e.Sig},
+		{Name: "topic0", Value: topic0},
+		{Name: "indexed", Value: indexed},
+		{Name: "data", Value: data},
+	}
+}
@@ -0,0 +1,222 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a decoded contract event: the envelope locating the log on chain
+// and the decoded event arguments
+type Event struct {
+	Chain       string
+	Network     string
+	BlockNumber uint64
+	BlockHash   string
+	Timestamp   time.Time
+	TxHash      string
+	TxIndex     uint64
+	LogIndex    uint64
+	Contract    string
+	Name        string
+	// Args holds every field that is not part of the envelope
+	Args map[string]interface{}
+
+	// present records the envelope fields the event was decoded with, nil
+	// when every field is known
+	present map[string]bool
+}
+
+// Key identifies the event, events in one transaction differ by log index.
+// Events decoded without a log index are told apart by contract, name and
+// arguments instead.
+func (e Event) Key() string {
+	if e.has("logIndex") {
+		return fmt.Sprintf("%s:%d", e.TxHash, e.LogIndex)
+	}
+	// maps are encoded with sorted keys, so equal arguments give equal keys
+	args, _ := json.Marshal(e.Args)
+	return fmt.Sprintf("%s:%s:%s:%s", e.TxHash, strings.ToLower(e.Contract), e.Name, args)
+}
+
+// has reports whether the event was decoded with any of the envelope keys
+func (e Event) has(keys ...string) bool {
+	if e.present == nil {
+		return true
+	}
+	for _, k := range keys {
+		if e.present[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// envelopeKeys are the fields of a decoded event which are not arguments
+var envelopeKeys = map[string]bool{
+	"chain": true, "network": true, "blockNumber": true, "blockHash": true,
+	"blockTimestamp": true, "timestamp": true, "transactionHash": true,
+	"transactionIndex": true, "logIndex": true, "contractAddress": true, "event": true,
+}
+
+// DecodeEvent decodes a contract event message. Numbers are kept as
+// json.Number so uint256 arguments are not rounded to float64.
+func DecodeEvent(b []byte) (Event, error) {
+	var fields map[string]interface{}
+	if err := decodeNumbers(b, &fields); err != nil {
+		return Event{}, fmt.Errorf("invalid event: %w", err)
+	}
+	if fields == nil {
+		return Event{}, errors.New("invalid event: not an object")
+	}
+	return NewEvent(fields)
+}
+
+// NewEvent builds an event from its decoded fields. Numbers given as
+// strings and timestamps given as unix seconds or RFC3339 are accepted.
+// Events without a block number or transaction hash, or with envelope
+// fields of the wrong type, are returned as far as they could be decoded
+// together with an error describing every problem.
+func NewEvent(fields map[string]interface{}) (Event, error) {
+	return newEvent(fields, true)
+}
+
+// NewPartialEvent is NewEvent for events that need not be located on chain,
+// such as logs decoded offline, so no field is required
+func NewPartialEvent(fields map[string]interface{}) (Event, error) {
+	return newEvent(fields, false)
+}
+
+func newEvent(fields map[string]interface{}, located bool) (Event, error) {
+	d := eventDecoder{fields: fields}
+	e := Event{
+		Chain:       d.str("chain", false),
+		Network:     d.str("network", false),
+		BlockNumber: d.uint("blockNumber", located),
+		BlockHash:   d.str("blockHash", false),
+		Timestamp:   d.time("blockTimestamp", "timestamp"),
+		TxHash:      d.str("transactionHash", located),
+		TxIndex:     d.uint("transactionIndex", false),
+		LogIndex:    d.uint("logIndex", false),
+		Contract:    d.str("contractAddress", false),
+		Name:        d.str("event", false),
+		Args:        make(map[string]interface{}, len(fields)),
+		present:     make(map[string]bool, len(envelopeKeys)),
+	}
+	for k, v := range fields {
+		if envelopeKeys[k] {
+			e.present[k] = v != nil
+		} else {
+			e.Args[k] = v
+		}
+	}
+
+	if len(d.problems) > 0 {
+		name := "invalid event"
+		if e.TxHash != "" {
+			name += " in " + e.TxHash
+		}
+		return e, fmt.Errorf("%s: %s", name, strings.Join(d.problems, "; "))
+	}
+	return e, nil
+}
+
+// UnmarshalJSON decodes a page of events keeping numbers exact
+func (d *EventDetails) UnmarshalJSON(b []byte) error {
+	type details EventDetails
+	return decodeNumbers(b, (*details)(d))
+}
+
+func decodeNumbers(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// eventDecoder reads envelope fields, collecting problems instead of failing
+// on the first one
+type eventDecoder struct {
+	fields   map[string]interface{}
+	problems []string
+}
+
+func (d *eventDecoder) value(key string, required bool) (interface{}, bool) {
+	v, ok := d.fields[key]
+	if !ok || v == nil {
+		if required {
+			d.problems = append(d.problems, key+" is missing")
+		}
+		return nil, false
+	}
+	return v, true
+}
+
+func (d *eventDecoder) invalid(key string, v interface{}, want string) {
+	d.problems = append(d.problems, fmt.Sprintf("%s: expected %s, got %v", key, want, v))
+}
+
+func (d *eventDecoder) str(key string, required bool) string {
+	v, ok := d.value(key, required)
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.invalid(key, v, "string")
+	}
+	return s
+}
+
+func (d *eventDecoder) uint(key string, required bool) uint64 {
+	v, ok := d.value(key, required)
+	if !ok {
+		return 0
+	}
+	if n, ok := parseUint(v); ok {
+		return n
+	}
+	d.invalid(key, v, "unsigned integer")
+	return 0
+}
+
+// time reads the first of keys present as unix seconds or RFC3339
+func (d *eventDecoder) time(keys ...string) time.Time {
+	for _, key := range keys {
+		v, ok := d.value(key, false)
+		if !ok {
+			continue
+		}
+		if sec, ok := parseUint(v); ok {
+			return time.Unix(int64(sec), 0)
+		}
+		if s, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t
+			}
+		}
+		d.invalid(key, v, "unix or RFC3339 time")
+		return time.Time{}
+	}
+	return time.Time{}
+}
+
+// parseUint accepts JSON numbers and decimal or hex strings
+func parseUint(v interface{}) (uint64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		u, err := strconv.ParseUint(n.String(), 10, 64)
+		return u, err == nil
+	case float64:
+		if n >= 0 && n == float64(uint64(n)) {
+			return uint64(n), true
+		}
+	case string:
+		u, err := strconv.ParseUint(n, 0, 64)
+		return u, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+)
+
+func decode(t *testing.T, msg string) Event {
+	t.Helper()
+	e, err := DecodeEvent([]byte(msg))
+	if err != nil {
+		t.Fatalf("DecodeEvent(%s): %v", msg, err)
+	}
+	return e
+}
+
+func TestEventKeyWithLogIndex(t *testing.T) {
+	a := decode(t, `{"blockNumber": 7, "transactionHash": "0xaa", "logIndex": 3, "event": "Transfer", "wad": 1}`)
+	b := decode(t, `{"blockNumber": 7, "transactionHash": "0xaa", "logIndex": 4, "event": "Transfer", "wad": 1}`)
+	if a.Key() != "0xaa:3" {
+		t.Errorf("Key = %s, want 0xaa:3", a.Key())
+	}
+	if a.Key() == b.Key() {
+		t.Error("logs with different log indexes share a key")
+	}
+}
+
+func TestEventKeyWithoutLogIndex(t *testing.T) {
+	// payloads without logIndex, several logs of one transaction
+	msgs := []string{
+		`{"blockNumber": 7, "transactionHash": "0xaa", "contractAddress": "0xC0", "event": "Transfer", "src": "0x01", "wad": 1}`,
+		`{"blockNumber": 7, "transactionHash": "0xaa", "contractAddress": "0xC0", "event": "Transfer", "src": "0x01", "wad": 2}`,
+		`{"blockNumber": 7, "transactionHash": "0xaa", "contractAddress": "0xC0", "event": "Approval", "src": "0x01", "wad": 1}`,
+		`{"blockNumber": 7, "transactionHash": "0xaa", "contractAddress": "0xD0", "event": "Transfer", "src": "0x01", "wad": 1}`,
+		`{"blockNumber": 7, "transactionHash": "0xbb", "contractAddress": "0xC0", "event": "Transfer", "src": "0x01", "wad": 1}`,
+	}
+	keys := map[string]string{}
+	for _, m := range msgs {
+		k := decode(t, m).Key()
+		if prev, ok := keys[k]; ok {
+			t.Errorf("%s and %s share key %s", prev, m, k)
+		}
+		keys[k] = m
+	}
+
+	// the same log replayed with fields in another order keeps its key
+	a := decode(t, msgs[0])
+	b := decode(t, `{"wad": 1, "src": "0x01", "event": "Transfer", "contractAddress": "0xc0", "transactionHash": "0xaa", "blockNumber": 7}`)
+	if a.Key() != b.Key() {
+		t.Errorf("replayed log keys differ: %s and %s", a.Key(), b.Key())
+	}
+}
+
+func TestTransferKey(t *testing.T) {
+	base := FungibleTokenTransfer{TxHash: "0xAA", TokenAddress: "0xT", FromAddress: "0x01", ToAddress: "0x02", Amount: big.NewInt(5), Position: 9}
+
+	// a batch payout and a router hop in the same transaction
+	others := []FungibleTokenTransfer{base, base, base, base}
+	others[0].ToAddress = "0x03"
+	others[1].Amount = big.NewInt(6)
+	others[2].FromAddress = "0x02"
+	others[3].TokenAddress = "0xU"
+	for _, o := range others {
+		if o.Key() == base.Key() {
+			t.Errorf("%+v shares key %s with %+v", o, o.Key(), base)
+		}
+	}
+
+	listed := Transfer{TxHash: "0xaa", TokenAddress: "0xt", FromAddress: "0x01", ToAddress: "0x02", Amount: big.NewInt(5), Position: 9}
+	if listed.Key() != base.Key() {
+		t.Errorf("listed key %s differs from streamed key %s", listed.Key(), base.Key())
+	}
+}
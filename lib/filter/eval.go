@@ -0,0 +1,222 @@
+package filter
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/timerange"
+)
+
+type node interface {
+	eval(r format.Record) (interface{}, error)
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicNode) eval(r format.Record) (interface{}, error) {
+	left, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	// both sides are evaluated so unknown fields are reported on every record
+	right, err := n.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if n.and {
+		return truthy(left) && truthy(right), nil
+	}
+	return truthy(left) || truthy(right), nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(r format.Record) (interface{}, error) {
+	v, err := n.operand.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(format.Record) (interface{}, error) {
+	return n.value, nil
+}
+
+// aliases map API field names to record field names
+var aliases = map[string]string{
+	"from_address":    "from",
+	"to_address":      "to",
+	"token_address":   "token",
+	"chain_name":      "chain",
+	"transactionHash": "tx_hash",
+	"blockNumber":     "block",
+	"blockHash":       "block_hash",
+	"blockTimestamp":  "timestamp",
+	"contractAddress": "contract",
+	"logIndex":        "log_index",
+	"time":            "timestamp",
+}
+
+// fieldNode looks up a record field. Names which are not fields of the
+// record are an error, strings must be quoted.
+type fieldNode struct {
+	name string
+}
+
+func (n *fieldNode) eval(r format.Record) (interface{}, error) {
+	if v, ok := r.Get(n.name); ok {
+		return v, nil
+	}
+	if alias, ok := aliases[n.name]; ok {
+		if v, ok := r.Get(alias); ok {
+			return v, nil
+		}
+	}
+	// decoded event arguments, either named on their own or as args.<name>.
+	// Events without the argument have no value for args.<name>.
+	if v, ok := r.Get("args." + n.name); ok {
+		return v, nil
+	}
+	if strings.HasPrefix(n.name, "args.") {
+		if _, ok := r.Get("args"); ok {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown field %q (fields: %s), quote strings as '%s'", n.name, strings.Join(r.Names(), ", "), n.name)
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(r format.Record) (interface{}, error) {
+	a, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := n.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || b == nil {
+		return (n.op == "!=") != (a == nil && b == nil), nil
+	}
+
+	c, ok := compare(a, b)
+	if !ok {
+		return n.op == "!=", nil
+	}
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	}
+	return false, nil
+}
+
+type matchNode struct {
+	negate bool
+	left   node
+	re     *regexp.Regexp
+}
+
+func (n *matchNode) eval(r format.Record) (interface{}, error) {
+	v, err := n.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	return v != nil && n.re.MatchString(format.Cell(v)) != n.negate, nil
+}
+
+type inNode struct {
+	negate bool
+	left   node
+	values []interface{}
+}
+
+func (n *inNode) eval(r format.Record) (interface{}, error) {
+	v, err := n.left.eval(r)
+	if err != nil || v == nil {
+		return n.negate, err
+	}
+	for _, item := range n.values {
+		if c, ok := compare(v, item); ok && c == 0 {
+			return !n.negate, nil
+		}
+	}
+	return n.negate, nil
+}
+
+// compare orders a and b, as numbers when both are numeric, as times when a
+// is a time and as case insensitive strings otherwise
+func compare(a, b interface{}) (int, bool) {
+	if ra, ok := format.Rat(a); ok {
+		if rb, ok := format.Rat(b); ok {
+			return ra.Cmp(rb), true
+		}
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := toTime(b)
+		if !ok {
+			return 0, false
+		}
+		return ta.Compare(tb), true
+	}
+
+	return strings.Compare(strings.ToLower(format.Cell(a)), strings.ToLower(format.Cell(b))), true
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := timerange.ParseTime(t, time.Now())
+		return parsed, err == nil
+	case *big.Rat:
+		if t.IsInt() {
+			return time.Unix(t.Num().Int64(), 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func truthy(v interface{}) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case string:
+		return b != ""
+	}
+	if r, ok := format.Rat(v); ok {
+		return r.Sign() != 0
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+// Package filter implements the --where expression language used to select
+// records on the client.
+//
+// An expression compares record fields with literals, eg.
+//
+//	amount > 1m and to in @watchlist.txt
+//	symbol == "usdc" or (symbol == 'USDT' && amount >= 250_000.5)
+//	event == "Transfer" and args.wad > 10
+//
+// Fields are the names shown by -o json, with dotted names reaching into
+// nested values such as decoded event arguments. Names which are not fields
+// are an error, so strings must be quoted; hex literals such as addresses
+// need no quotes. Amounts are compared as decimals using the token's
+// decimals, numbers may use _ separators and the k, m and b suffixes.
+//
+// Operators are ==, !=, <, <=, >, >=, =~ and !~ (regular expressions),
+// in and not in (a [list] or @file with one value per line), and, or, not
+// and parentheses. String equality ignores case.
+package filter
+
+import (
+	"fmt"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+	"github.com/spf13/pflag"
+)
+
+// Expr is a compiled filter expression
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse compiles a filter expression
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", src, err)
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q at %d", p.peek().text, p.peek().pos+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Match reports whether the record satisfies the expression. A nil
+// expression matches every record. It fails when the expression names a
+// field the record does not have.
+func (e *Expr) Match(r format.Record) (bool, error) {
+	if e == nil {
+		return true, nil
+	}
+	v, err := e.root.eval(r)
+	if err != nil {
+		return false, fmt.Errorf("invalid filter %q: %w", e.src, err)
+	}
+	return truthy(v), nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Flags reads filter expressions from repeated --where flags
+type Flags struct {
+	where []string
+}
+
+// WhereFlag registers --where on fs
+func WhereFlag(fs *pflag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringArrayVar(&f.where, "where", nil, `Only records matching this expression, eg. 'amount > 1m and to in @watchlist.txt' (repeat to combine with and)`)
+	return f
+}
+
+// Expr compiles the --where flags, returning nil when none were given
+func (f *Flags) Expr() (*Expr, error) {
+	var combined *Expr
+	for _, src := range f.where {
+		e, err := Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		if combined == nil {
+			combined = e
+			continue
+		}
+		combined = &Expr{
+			src:  combined.src + " and " + e.src,
+			root: &logicNode{and: true, left: combined.root, right: e.root},
+		}
+	}
+	return combined, nil
+}
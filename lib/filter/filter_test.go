@@ -0,0 +1,215 @@
+package filter
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+)
+
+// transfer is a record shaped like those of transfer list
+var transfer = format.Record{
+	{Name: "from", Value: "0xAbC0000000000000000000000000000000000001"},
+	{Name: "to", Value: "0x0000000000000000000000000000000000000002"},
+	{Name: "token", Value: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+	{Name: "symbol", Value: "USDC"},
+	{Name: "amount", Value: format.Amount{Value: big.NewInt(1_500_000_000_000), Decimals: 6}},
+	{Name: "block", Value: uint64(19_000_000)},
+	{Name: "timestamp", Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+}
+
+// event is a record shaped like those of event list with decoded arguments
+var event = format.Record{
+	{Name: "event", Value: "Transfer"},
+	{Name: "block", Value: uint64(100)},
+	{Name: "args", Value: map[string]interface{}{"wad": big.NewInt(42), "src": "0x01"}},
+}
+
+func match(t *testing.T, src string, r format.Record) bool {
+	t.Helper()
+	e, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", src, err)
+	}
+	ok, err := e.Match(r)
+	if err != nil {
+		t.Fatalf("Match(%q): %v", src, err)
+	}
+	return ok
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		src  string
+		r    format.Record
+		want bool
+	}{
+		{`symbol == "usdc"`, transfer, true},
+		{`symbol = 'USDC'`, transfer, true},
+		{`symbol != "usdt"`, transfer, true},
+		{`amount > 1m`, transfer, true},
+		{`amount >= 1_500_000`, transfer, true},
+		{`amount > 1.5m`, transfer, false},
+		{`amount < 2b`, transfer, true},
+		{`amount == 1500k`, transfer, true},
+		{`block <= 19000000 and block > 18_999_999`, transfer, true},
+		{`amount > 1m and symbol == "usdt"`, transfer, false},
+		{`amount > 1m && (symbol == "usdt" || symbol == "usdc")`, transfer, true},
+		{`not symbol == "usdc"`, transfer, false},
+		{`!(amount < 1k)`, transfer, true},
+		{`from == 0xabc0000000000000000000000000000000000001`, transfer, true},
+		{`symbol in ["usdt", "usdc"]`, transfer, true},
+		{`symbol not in ["usdt", "dai"]`, transfer, true},
+		{`block in [1, 19m]`, transfer, true},
+		{`symbol =~ "^US"`, transfer, true},
+		{`symbol !~ "DAI|USDT"`, transfer, true},
+		{`timestamp > "2024-02-01"`, transfer, true},
+		{`time < "2024-03-01T11:00:00Z"`, transfer, false},
+		{`time <= "2024-03-01T12:00:00Z"`, transfer, true},
+		{`from_address == 0xabc0000000000000000000000000000000000001`, transfer, true},
+		{`event == "Transfer" and args.wad > 10`, event, true},
+		{`wad == 42`, event, true},
+		{`args.guy == "0x01"`, event, false},
+		{`args.guy != "0x01"`, event, true},
+		{`args.src in ["0x01"]`, event, true},
+	}
+	for _, tt := range tests {
+		if got := match(t, tt.src, tt.r); got != tt.want {
+			t.Errorf("%s: matched %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestMatchUnknownField(t *testing.T) {
+	for _, src := range []string{
+		`symbol == usdc`,
+		`sender == "0x01"`,
+		`amount > 1 or sender == "0x01"`,
+		`symbol in ["usdc"] and not recipient`,
+	} {
+		e, err := Parse(src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", src, err)
+		}
+		if _, err := e.Match(transfer); err == nil || !strings.Contains(err.Error(), "unknown field") {
+			t.Errorf("%s: err = %v, want unknown field", src, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`symbol == "usdc`, "unterminated string"},
+		{`symbol in [usdc]`, "must be quoted"},
+		{`symbol in ["usdc" "usdt"]`, "expected , or ]"},
+		{`symbol in "usdc"`, "expected [list] or @file"},
+		{`symbol =~ US`, "quoted regular expression"},
+		{`symbol =~ "("`, "invalid regular expression"},
+		{`(amount > 1`, "missing )"},
+		{`amount >`, "unexpected end"},
+		{`amount > 1 1`, "unexpected"},
+		{`amount > 1x`, "invalid number"},
+		{`and == 1`, "unexpected"},
+		{`amount # 1`, "unexpected"},
+		{`to in @`, "missing file name"},
+		{`to in @does-not-exist.txt`, "read list"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) err = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestLexNumbersAndHex(t *testing.T) {
+	tokens, err := lex(`amount > -1_000.5k and to == 0xAB12`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		kind tokenKind
+		text string
+	}{
+		{tokIdent, "amount"}, {tokOp, ">"}, {tokNumber, "-1_000.5k"},
+		{tokIdent, "and"}, {tokIdent, "to"}, {tokOp, "=="}, {tokString, "0xAB12"}, {tokEOF, ""},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, w := range want {
+		if tokens[i].kind != w.kind || tokens[i].text != w.text {
+			t.Errorf("token %d = %v %q, want %v %q", i, tokens[i].kind, tokens[i].text, w.kind, w.text)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]string{
+		"10":        "10",
+		"1_000":     "1000",
+		"2.5k":      "2500",
+		"1M":        "1000000",
+		"0.25b":     "250000000",
+		"-3":        "-3",
+		"250_000.5": "500001/2",
+	}
+	for in, want := range tests {
+		r, err := parseNumber(in)
+		if err != nil {
+			t.Errorf("parseNumber(%q): %v", in, err)
+			continue
+		}
+		if r.RatString() != want {
+			t.Errorf("parseNumber(%q) = %s, want %s", in, r.RatString(), want)
+		}
+	}
+}
+
+func TestInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.txt")
+	list := "# watched addresses\n\n0x0000000000000000000000000000000000000002\n  0x03  \n"
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !match(t, "to in @"+path, transfer) {
+		t.Error("to not found in watchlist")
+	}
+	if match(t, "from in @"+path, transfer) {
+		t.Error("from found in watchlist")
+	}
+}
+
+func TestFlagsCombineWithAnd(t *testing.T) {
+	f := &Flags{where: []string{`amount > 1m`, `symbol == "usdt" or symbol == "usdc"`}}
+	e, err := f.Expr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := e.Match(transfer); err != nil || !ok {
+		t.Errorf("Match = %v, %v, want true", ok, err)
+	}
+
+	f.where = append(f.where, `block < 100`)
+	if e, err = f.Expr(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := e.Match(transfer); ok {
+		t.Error("matched although block < 100 is false")
+	}
+
+	if e, _ := (&Flags{}).Expr(); e != nil {
+		t.Error("Expr without --where is not nil")
+	}
+	var none *Expr
+	if ok, err := none.Match(transfer); !ok || err != nil {
+		t.Errorf("nil Expr Match = %v, %v, want true", ok, err)
+	}
+}
@@ -0,0 +1,236 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is one of the given keywords or operators
+func (p *parser) is(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokIdent && t.kind != tokOp {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.is("or", "||") {
+		p.next()
+		var right node
+		if right, err = p.parseAnd(); err == nil {
+			left = &logicNode{left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	for err == nil && p.is("and", "&&") {
+		p.next()
+		var right node
+		if right, err = p.parseNot(); err == nil {
+			left = &logicNode{and: true, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.is("not", "!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand}, nil
+	}
+	return p.parseCompare()
+}
+
+var compareOps = map[string]string{"==": "==", "=": "==", "!=": "!=", ">": ">", ">=": ">=", "<": "<", "<=": "<="}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokOp && compareOps[t.text] != "":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: compareOps[t.text], left: left, right: right}, nil
+
+	case t.kind == tokOp && (t.text == "=~" || t.text == "!~"):
+		p.next()
+		lit := p.next()
+		if lit.kind != tokString {
+			return nil, fmt.Errorf("%s at %d needs a quoted regular expression", t.text, t.pos+1)
+		}
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", lit.text, err)
+		}
+		return &matchNode{negate: t.text == "!~", left: left, re: re}, nil
+
+	case p.is("in"):
+		p.next()
+		return p.parseIn(left, false)
+
+	case p.is("not") && p.pos+1 < len(p.tokens) && strings.EqualFold(p.tokens[p.pos+1].text, "in"):
+		p.next()
+		p.next()
+		return p.parseIn(left, true)
+	}
+	return left, nil
+}
+
+func (p *parser) parseIn(left node, negate bool) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokFile:
+		values, err := readList(t.text)
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{negate: negate, left: left, values: values}, nil
+
+	case tokLBracket:
+		var values []interface{}
+		for p.peek().kind != tokRBracket {
+			if len(values) > 0 {
+				if p.next().kind != tokComma {
+					return nil, fmt.Errorf("expected , or ] at %d", p.tokens[p.pos-1].pos+1)
+				}
+			}
+			item := p.next()
+			switch item.kind {
+			case tokNumber:
+				r, err := parseNumber(item.text)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, r)
+			case tokString:
+				values = append(values, item.text)
+			case tokIdent:
+				return nil, fmt.Errorf("list value %s at %d must be quoted", item.text, item.pos+1)
+			default:
+				return nil, fmt.Errorf("expected a list value at %d", item.pos+1)
+			}
+		}
+		p.next()
+		return &inNode{negate: negate, left: left, values: values}, nil
+	}
+	return nil, fmt.Errorf("expected [list] or @file after in at %d", t.pos+1)
+}
+
+var keywords = map[string]bool{"and": true, "or": true, "not": true, "in": true}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing ) for ( at %d", t.pos+1)
+		}
+		return n, nil
+	case tokNumber:
+		r, err := parseNumber(t.text)
+		if err != nil {
+			return nil, err
+		}
+		return &literalNode{r}, nil
+	case tokString:
+		return &literalNode{t.text}, nil
+	case tokIdent:
+		if keywords[strings.ToLower(t.text)] {
+			return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos+1)
+		}
+		return &fieldNode{t.text}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos+1)
+}
+
+var numberSuffixes = map[byte]int64{'k': 1e3, 'm': 1e6, 'b': 1e9}
+
+// parseNumber parses a decimal number with optional _ separators and a k, m
+// or b suffix
+func parseNumber(s string) (*big.Rat, error) {
+	text := strings.ReplaceAll(s, "_", "")
+	mult := int64(1)
+	if n := len(text); n > 0 {
+		if m, ok := numberSuffixes[strings.ToLower(text)[n-1]]; ok {
+			mult = m
+			text = text[:n-1]
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return r.Mul(r, new(big.Rat).SetInt64(mult)), nil
+}
+
+// readList reads the values of an in @file list, one per line. Blank lines
+// and lines starting with # are ignored.
+func readList(path string) ([]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read list: %w", err)
+	}
+	defer f.Close()
+
+	var values []interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			values = append(values, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read list %s: %w", path, err)
+	}
+	return values, nil
+}
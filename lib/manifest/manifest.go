@@ -0,0 +1,242 @@
+// Package manifest reads contract manifests, the declarative list of
+// contracts a project wants indexed, and plans how to register them
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+	"github.com/heimdahl-xyz/heimdahl-cli/lib/artifact"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the contracts of a project. Chain and network are the
+// defaults of contracts that do not set their own.
+type Manifest struct {
+	Project   string     `yaml:"project"`
+	Chain     string     `yaml:"chain"`
+	Network   string     `yaml:"network"`
+	Contracts []Contract `yaml:"contracts"`
+}
+
+// Contract is a contract to register
+type Contract struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Chain   string `yaml:"chain"`
+	Network string `yaml:"network"`
+	// ABIFile is an ABI or build artifact path, relative to the manifest
+	ABIFile string   `yaml:"abi"`
+	Events  []string `yaml:"events"`
+
+	// ABI is read from ABIFile
+	ABI json.RawMessage `yaml:"-"`
+}
+
+// Params returns the payload registering the contract for the project
+func (c Contract) Params(project string) lib.ContractParams {
+	params := lib.ContractParams{
+		ProjectName:     project,
+		Chain:           c.Chain,
+		Network:         c.Network,
+		ContractAddress: c.Address,
+		ContractName:    c.Name,
+	}
+	if len(c.Events) > 0 {
+		events := strings.Join(c.Events, ",")
+		params.EventNames = &events
+	}
+	if len(c.ABI) > 0 {
+		raw := string(c.ABI)
+		params.RawABI = &raw
+	}
+	return params
+}
+
+// Read reads and validates a manifest, loading the ABI files it refers to
+func Read(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if err := m.load(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// load applies the defaults, reads ABI files relative to dir and checks
+// that every contract is complete and listed once
+func (m *Manifest) load(dir string) error {
+	if len(m.Contracts) == 0 {
+		return errors.New("no contracts")
+	}
+
+	var problems []string
+	seen := map[string]int{}
+	for i := range m.Contracts {
+		c := &m.Contracts[i]
+		if c.Chain == "" {
+			c.Chain = m.Chain
+		}
+		if c.Network == "" {
+			c.Network = m.Network
+		}
+		c.Address = strings.TrimSpace(c.Address)
+		for j := range c.Events {
+			c.Events[j] = strings.TrimSpace(c.Events[j])
+		}
+
+		name := fmt.Sprintf("contract %d", i+1)
+		if c.Name != "" {
+			name += " (" + c.Name + ")"
+		}
+		var missing []string
+		for _, f := range []struct{ key, value string }{
+			{"name", c.Name}, {"address", c.Address}, {"chain", c.Chain}, {"network", c.Network},
+		} {
+			if f.value == "" {
+				missing = append(missing, f.key)
+			}
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: missing %s", name, strings.Join(missing, ", ")))
+			continue
+		}
+
+		key := c.Key()
+		if j, ok := seen[key]; ok {
+			problems = append(problems, fmt.Sprintf("%s: %s is already listed as contract %d", name, c.Address, j+1))
+			continue
+		}
+		seen[key] = i
+
+		if c.ABIFile != "" {
+			path := c.ABIFile
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+				continue
+			}
+			if c.ABI, err = artifact.ABI(b); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: %s", name, c.ABIFile, err))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Key identifies the contract on its chain network, EVM addresses are case
+// insensitive
+func (c Contract) Key() string {
+	return contractKey(c.Chain, c.Network, c.Address)
+}
+
+func contractKey(chain, network, address string) string {
+	return strings.ToLower(chain + "." + network + "." + address)
+}
+
+// Networks returns the chain networks of the manifest's contracts as
+// [chain, network] pairs in the order they first appear
+func (m *Manifest) Networks() [][2]string {
+	var networks [][2]string
+	seen := map[[2]string]bool{}
+	for _, c := range m.Contracts {
+		n := [2]string{c.Chain, c.Network}
+		if !seen[n] {
+			seen[n] = true
+			networks = append(networks, n)
+		}
+	}
+	return networks
+}
+
+// Action is what applying the manifest does to a contract
+type Action string
+
+const (
+	// Create registers a contract that is not indexed yet
+	Create Action = "create"
+	// Unchanged is a contract already indexed as listed
+	Unchanged Action = "unchanged"
+	// Differs is a contract already indexed with another name or events.
+	// Registered contracts are never modified.
+	Differs Action = "differs"
+)
+
+// Step is the planned action for one contract of the manifest
+type Step struct {
+	Contract Contract
+	Action   Action
+	// Changes describes how an indexed contract differs from the manifest
+	Changes []string
+}
+
+// Plan compares the manifest with the contracts already indexed
+func (m *Manifest) Plan(indexed []lib.ContractInfo) []Step {
+	existing := make(map[string]lib.ContractInfo, len(indexed))
+	for _, info := range indexed {
+		existing[contractKey(info.Chain, info.Network, info.ContractAddress)] = info
+	}
+
+	steps := make([]Step, 0, len(m.Contracts))
+	for _, c := range m.Contracts {
+		info, ok := existing[c.Key()]
+		if !ok {
+			steps = append(steps, Step{Contract: c, Action: Create})
+			continue
+		}
+
+		var changes []string
+		if info.ContractName != c.Name {
+			changes = append(changes, fmt.Sprintf("name %q, listed %q", info.ContractName, c.Name))
+		}
+		if len(c.Events) > 0 && !sameNames(info.EventNames(), c.Events) {
+			changes = append(changes, fmt.Sprintf("events %s, listed %s", strings.Join(info.EventNames(), ","), strings.Join(c.Events, ",")))
+		}
+		action := Unchanged
+		if len(changes) > 0 {
+			action = Differs
+		}
+		steps = append(steps, Step{Contract: c, Action: action, Changes: changes})
+	}
+	return steps
+}
+
+// sameNames reports whether a and b hold the same names in any order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/lib"
+)
+
+const weth = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "abi/weth.json", `{"abi": [{"type": "event", "name": "Deposit", "inputs": []}]}`)
+	path := write(t, dir, "contracts.yaml", `
+project: my-dapp
+chain: ethereum
+network: mainnet
+contracts:
+  - name: WETH
+    address: " `+weth+` "
+    abi: abi/weth.json
+    events: [Transfer, " Deposit "]
+  - name: USDbC
+    chain: base
+    address: "0xd9aAEc86B65D86f6A7B5B1b0c42FFA531710b6CA"
+`)
+
+	m, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Contracts) != 2 {
+		t.Fatalf("%d contracts, want 2", len(m.Contracts))
+	}
+	c := m.Contracts[0]
+	if c.Address != weth || c.Chain != "ethereum" || c.Network != "mainnet" || strings.Join(c.Events, ",") != "Transfer,Deposit" {
+		t.Errorf("contract = %+v", c)
+	}
+	if !strings.HasPrefix(string(c.ABI), "[") {
+		t.Errorf("ABI not read from the artifact: %s", c.ABI)
+	}
+	if c := m.Contracts[1]; c.Chain != "base" || c.Network != "mainnet" || c.ABI != nil {
+		t.Errorf("contract = %+v, want base mainnet without ABI", c)
+	}
+	if n := m.Networks(); len(n) != 2 || n[0] != [2]string{"ethereum", "mainnet"} || n[1] != [2]string{"base", "mainnet"} {
+		t.Errorf("Networks = %v", n)
+	}
+
+	params := c.Params(m.Project)
+	if params.ProjectName != "my-dapp" || params.EventNames == nil || *params.EventNames != "Transfer,Deposit" || params.RawABI == nil {
+		t.Errorf("Params = %+v", params)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		manifest string
+		want     string
+	}{
+		{`contracts: []`, "no contracts"},
+		{`contract: []`, "field contract not found"},
+		{"contracts:\n  - name: WETH\n    chain: ethereum", "missing address, network"},
+		{"chain: ethereum\nnetwork: mainnet\ncontracts:\n  - {name: A, address: \"" + weth + "\"}\n  - {name: B, address: \"" + strings.ToLower(weth) + "\"}",
+			"is already listed as contract 1"},
+		{"chain: ethereum\nnetwork: mainnet\ncontracts:\n  - {name: A, address: \"" + weth + "\", abi: missing.json}", "missing.json"},
+	}
+	for _, tt := range tests {
+		path := write(t, t.TempDir(), "contracts.yaml", tt.manifest)
+		_, err := Read(path)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Read(%q) err = %v, want %q", tt.manifest, err, tt.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := &Manifest{Contracts: []Contract{
+		{Name: "WETH", Chain: "ethereum", Network: "mainnet", Address: weth, Events: []string{"Transfer", "Deposit"}},
+		{Name: "Renamed", Chain: "ethereum", Network: "mainnet", Address: "0x01"},
+		{Name: "Events", Chain: "ethereum", Network: "mainnet", Address: "0x02", Events: []string{"Swap"}},
+		{Name: "Any", Chain: "ethereum", Network: "mainnet", Address: "0x03"},
+		{Name: "New", Chain: "ethereum", Network: "mainnet", Address: "0x04"},
+		// indexed on another network only
+		{Name: "WETH", Chain: "ethereum", Network: "sepolia", Address: weth},
+	}}
+	indexed := []lib.ContractInfo{
+		{Chain: "ethereum", Network: "mainnet", ContractName: "WETH", ContractAddress: strings.ToLower(weth), Events: "Deposit, Transfer"},
+		{Chain: "ethereum", Network: "mainnet", ContractName: "Old", ContractAddress: "0x01"},
+		{Chain: "ethereum", Network: "mainnet", ContractName: "Events", ContractAddress: "0x02", Events: "Swap,Sync"},
+		{Chain: "ethereum", Network: "mainnet", ContractName: "Any", ContractAddress: "0x03", Events: "Swap"},
+	}
+
+	steps := m.Plan(indexed)
+	want := []struct {
+		action  Action
+		changes string
+	}{
+		{Unchanged, ""},
+		{Differs, `name "Old", listed "Renamed"`},
+		{Differs, "events Swap,Sync, listed Swap"},
+		// contracts listing no events accept any
+		{Unchanged, ""},
+		{Create, ""},
+		{Create, ""},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("%d steps, want %d", len(steps), len(want))
+	}
+	for i, w := range want {
+		s := steps[i]
+		if s.Contract.Name != m.Contracts[i].Name || s.Action != w.action || strings.Join(s.Changes, "; ") != w.changes {
+			t.Errorf("step %d = %s %s %v, want %s %q", i, s.Contract.Name, s.Action, s.Changes, w.action, w.changes)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package pattern handles the search patterns used to select transfers,
+// swaps and events.
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadFile reads patterns from path, one per line. Blank lines and lines
+// starting with # are ignored. A path of "-" reads standard input.
+func ReadFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("read patterns: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read patterns from %s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// FromArgs combines patterns given as arguments with those read from file,
+// if set
+func FromArgs(args []string, file string) ([]string, error) {
+	if file == "" {
+		return args, nil
+	}
+	fromFile, err := ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]string{}, args...), fromFile...), nil
+}
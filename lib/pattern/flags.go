@@ -0,0 +1,133 @@
+package pattern
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Flags builds patterns of one kind from command line flags. Flags set on
+// the command line also override the matching segments of patterns given
+// as arguments.
+type Flags struct {
+	spec   spec
+	fs     *pflag.FlagSet
+	values []string
+}
+
+// flagUsage documents the flag of each segment
+var flagUsage = map[string]string{
+	"chain":   "Chain of the pattern (eg. ethereum)",
+	"network": "Network of the pattern",
+	"token":   "Token symbol or address (default all)",
+	"token1":  "First token symbol or address (default all)",
+	"token2":  "Second token symbol or address (default all)",
+	"from":    "Sender address (default all)",
+	"to":      "Recipient address (default all)",
+	"size":    "Size bucket (default all)",
+	"address": "Contract address",
+	"event":   "Event name (default all)",
+}
+
+// TransferFlags registers --chain, --network, --token, --from, --to and --size on fs
+func TransferFlags(fs *pflag.FlagSet) *Flags {
+	return newFlags(transferSpec, fs)
+}
+
+// SwapFlags registers --chain, --network, --token1, --token2 and --size on fs
+func SwapFlags(fs *pflag.FlagSet) *Flags {
+	return newFlags(swapSpec, fs)
+}
+
+// EventFlags registers --chain, --network, --address and --event on fs
+func EventFlags(fs *pflag.FlagSet) *Flags {
+	return newFlags(eventSpec, fs)
+}
+
+func newFlags(s spec, fs *pflag.FlagSet) *Flags {
+	f := &Flags{spec: s, fs: fs, values: make([]string, len(s.segments))}
+	for i, seg := range s.segments {
+		def := ""
+		shorthand := ""
+		switch seg.name {
+		case "chain":
+			shorthand = "c"
+		case "network":
+			shorthand, def = "w", "mainnet"
+		}
+		fs.StringVarP(&f.values[i], seg.name, shorthand, def, flagUsage[seg.name])
+	}
+	return f
+}
+
+// Patterns parses and validates the patterns given as arguments, applying
+// flags set on the command line to each of them. Without arguments a single
+// pattern is built from the flags. Patterns are returned normalised and
+// without duplicates.
+func (f *Flags) Patterns(args []string) ([]string, error) {
+	if len(args) == 0 {
+		// the profile's chain applies as a default without marking the flag
+		// changed, so check its value
+		if f.value("chain") == "" {
+			return nil, fmt.Errorf(`a %s pattern (%s) or --chain is required, or set a default chain with "heimdahl config set chain"`, f.spec.kind, f.spec.names())
+		}
+		args = []string{""}
+	}
+
+	seen := map[string]bool{}
+	var patterns []string
+	for _, arg := range args {
+		values := make([]string, len(f.spec.segments))
+		ptrs := make([]*string, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if arg == "" {
+			copy(values, f.values)
+		} else if err := f.spec.parse(arg, ptrs); err != nil {
+			return nil, err
+		}
+
+		overridden := false
+		for i, seg := range f.spec.segments {
+			if f.fs.Changed(seg.name) {
+				values[i] = f.values[i]
+				overridden = true
+			}
+		}
+		if overridden || arg == "" {
+			if err := f.spec.validate(ptrs); err != nil {
+				return nil, err
+			}
+		}
+
+		if p := join(ptrs); !seen[p] {
+			seen[p] = true
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, nil
+}
+
+// value returns the value of the flag of the named segment
+func (f *Flags) value(name string) string {
+	for i, seg := range f.spec.segments {
+		if seg.name == name {
+			return f.values[i]
+		}
+	}
+	return ""
+}
+
+// Pattern is like Patterns for commands taking a single pattern
+func (f *Flags) Pattern(args []string) (string, error) {
+	patterns, err := f.Patterns(args)
+	if err != nil {
+		return "", err
+	}
+	if len(patterns) > 1 {
+		return "", fmt.Errorf("expected a single %s pattern, got %d", f.spec.kind, len(patterns))
+	}
+	return patterns[0], nil
+}
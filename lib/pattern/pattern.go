@@ -0,0 +1,308 @@
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Wildcard matches any value of a pattern segment. Empty segments and "*"
+// are normalised to it.
+const Wildcard = "all"
+
+// Chains lists the chains known to the API
+var Chains = []string{"ethereum", "base", "binance", "polygon", "solana", "tron", "arbitrum", "optimism"}
+
+// SizeBuckets lists the transfer and swap size buckets, from the smallest
+var SizeBuckets = []string{Wildcard, "small", "medium", "large", "whale"}
+
+// Error describes why a pattern is invalid
+type Error struct {
+	// Kind is transfer, swap or event
+	Kind    string
+	Pattern string
+	// Segment is the name of the invalid segment, empty when the pattern as
+	// a whole is malformed
+	Segment string
+	Reason  string
+}
+
+func (e *Error) Error() string {
+	if e.Segment == "" {
+		return fmt.Sprintf("invalid %s pattern %q: %s", e.Kind, e.Pattern, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s pattern %q: %s %s", e.Kind, e.Pattern, e.Segment, e.Reason)
+}
+
+// Transfer selects fungible token transfers:
+// chain.network.token.from.to.size
+type Transfer struct {
+	Chain   string
+	Network string
+	Token   string
+	From    string
+	To      string
+	Size    string
+}
+
+// ParseTransfer parses and validates a transfer pattern
+func ParseTransfer(s string) (Transfer, error) {
+	var t Transfer
+	err := transferSpec.parse(s, t.segments())
+	return t, err
+}
+
+// Validate checks every segment of the pattern and normalises wildcards
+// and letter case
+func (t *Transfer) Validate() error {
+	return transferSpec.validate(t.segments())
+}
+
+func (t Transfer) String() string {
+	return join(t.segments())
+}
+
+func (t *Transfer) segments() []*string {
+	return []*string{&t.Chain, &t.Network, &t.Token, &t.From, &t.To, &t.Size}
+}
+
+// Swap selects token swaps: chain.network.token1.token2.size
+type Swap struct {
+	Chain   string
+	Network string
+	Token1  string
+	Token2  string
+	Size    string
+}
+
+// ParseSwap parses and validates a swap pattern
+func ParseSwap(s string) (Swap, error) {
+	var sw Swap
+	err := swapSpec.parse(s, sw.segments())
+	return sw, err
+}
+
+// Validate checks every segment of the pattern and normalises wildcards
+// and letter case
+func (s *Swap) Validate() error {
+	return swapSpec.validate(s.segments())
+}
+
+func (s Swap) String() string {
+	return join(s.segments())
+}
+
+func (s *Swap) segments() []*string {
+	return []*string{&s.Chain, &s.Network, &s.Token1, &s.Token2, &s.Size}
+}
+
+// Event selects contract events: chain.network.address.Event
+type Event struct {
+	Chain   string
+	Network string
+	Address string
+	Event   string
+}
+
+// ParseEvent parses and validates an event pattern
+func ParseEvent(s string) (Event, error) {
+	var e Event
+	err := eventSpec.parse(s, e.segments())
+	return e, err
+}
+
+// Validate checks every segment of the pattern and normalises wildcards
+// and letter case
+func (e *Event) Validate() error {
+	return eventSpec.validate(e.segments())
+}
+
+func (e Event) String() string {
+	return join(e.segments())
+}
+
+func (e *Event) segments() []*string {
+	return []*string{&e.Chain, &e.Network, &e.Address, &e.Event}
+}
+
+// segment describes one dotted segment of a pattern
+type segment struct {
+	name string
+	// check validates and normalises value, given the chain of the pattern
+	check func(chain, value string) (string, error)
+}
+
+// spec describes the segments of a kind of pattern
+type spec struct {
+	kind     string
+	segments []segment
+}
+
+var transferSpec = spec{kind: "transfer", segments: []segment{
+	{"chain", checkChain},
+	{"network", checkNetwork},
+	{"token", wildcard(checkToken)},
+	{"from", wildcard(checkAddress)},
+	{"to", wildcard(checkAddress)},
+	{"size", wildcard(checkSize)},
+}}
+
+var swapSpec = spec{kind: "swap", segments: []segment{
+	{"chain", checkChain},
+	{"network", checkNetwork},
+	{"token1", wildcard(checkToken)},
+	{"token2", wildcard(checkToken)},
+	{"size", wildcard(checkSize)},
+}}
+
+var eventSpec = spec{kind: "event", segments: []segment{
+	{"chain", checkChain},
+	{"network", checkNetwork},
+	{"address", checkAddress},
+	{"event", wildcard(checkEventName)},
+}}
+
+// names returns the dotted segment names, eg. chain.network.address.event
+func (s spec) names() string {
+	names := make([]string, len(s.segments))
+	for i, seg := range s.segments {
+		names[i] = seg.name
+	}
+	return strings.Join(names, ".")
+}
+
+func (s spec) parse(pattern string, values []*string) error {
+	parts := strings.Split(strings.TrimSpace(pattern), ".")
+	if len(parts) != len(s.segments) {
+		return &Error{Kind: s.kind, Pattern: pattern,
+			Reason: fmt.Sprintf("expected %d segments %s, got %d", len(s.segments), s.names(), len(parts))}
+	}
+
+	for i, part := range parts {
+		*values[i] = part
+	}
+	return s.validate(values)
+}
+
+func (s spec) validate(values []*string) error {
+	pattern := join(values)
+	chain := strings.ToLower(strings.TrimSpace(*values[0]))
+
+	for i, seg := range s.segments {
+		v, err := seg.check(chain, strings.TrimSpace(*values[i]))
+		if err != nil {
+			return &Error{Kind: s.kind, Pattern: pattern, Segment: seg.name, Reason: err.Error()}
+		}
+		*values[i] = v
+	}
+	return nil
+}
+
+func join(values []*string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = *v
+	}
+	return strings.Join(parts, ".")
+}
+
+// wildcard allows a segment to match anything
+func wildcard(check func(chain, value string) (string, error)) func(chain, value string) (string, error) {
+	return func(chain, value string) (string, error) {
+		if value == "" || value == "*" || strings.EqualFold(value, Wildcard) {
+			return Wildcard, nil
+		}
+		return check(chain, value)
+	}
+}
+
+var (
+	networkRe   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	symbolRe    = regexp.MustCompile(`^[A-Za-z0-9]{1,20}$`)
+	eventNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	evmRe       = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	solanaRe    = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	tronRe      = regexp.MustCompile(`^T[1-9A-HJ-NP-Za-km-z]{33}$`)
+)
+
+func checkChain(_, value string) (string, error) {
+	value = strings.ToLower(value)
+	if value == "" {
+		return "", fmt.Errorf("is required")
+	}
+	for _, c := range Chains {
+		if c == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a known chain (known: %s)", value, strings.Join(Chains, ", "))
+}
+
+func checkNetwork(_, value string) (string, error) {
+	value = strings.ToLower(value)
+	if value == "" {
+		return "", fmt.Errorf("is required")
+	}
+	if !networkRe.MatchString(value) {
+		return "", fmt.Errorf("%q is not a valid network name", value)
+	}
+	return value, nil
+}
+
+// checkToken accepts a token symbol or a token address
+func checkToken(chain, value string) (string, error) {
+	if symbolRe.MatchString(value) {
+		return strings.ToLower(value), nil
+	}
+	if v, err := checkAddress(chain, value); err == nil {
+		return v, nil
+	}
+	return "", fmt.Errorf("%q is neither a token symbol nor a %s address", value, chain)
+}
+
+// checkAddress validates value against the address format of chain
+func checkAddress(chain, value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("is required")
+	}
+
+	switch chain {
+	case "solana":
+		if !solanaRe.MatchString(value) {
+			return "", fmt.Errorf("%q is not a valid solana address", value)
+		}
+	case "tron":
+		if !tronRe.MatchString(value) {
+			return "", fmt.Errorf("%q is not a valid tron address", value)
+		}
+	default:
+		if !evmRe.MatchString(value) {
+			return "", fmt.Errorf("%q is not a valid %s address (0x followed by 40 hex digits)", value, chain)
+		}
+		// mixed case addresses carry an EIP-55 checksum
+		if value != strings.ToLower(value) && value[2:] != strings.ToUpper(value[2:]) &&
+			common.HexToAddress(value).Hex() != value {
+			return "", fmt.Errorf("%q has an invalid checksum", value)
+		}
+	}
+	return value, nil
+}
+
+func checkSize(_, value string) (string, error) {
+	value = strings.ToLower(value)
+	for _, s := range SizeBuckets {
+		if s == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a size bucket (known: %s)", value, strings.Join(SizeBuckets, ", "))
+}
+
+func checkEventName(_, value string) (string, error) {
+	if !eventNameRe.MatchString(value) {
+		return "", fmt.Errorf("%q is not a valid event name", value)
+	}
+	return value, nil
+}
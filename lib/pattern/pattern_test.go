@@ -0,0 +1,191 @@
+package pattern
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	weth = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	bob  = "0x0000000000000000000000000000000000000002"
+)
+
+func TestParseTransfer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ethereum.mainnet.usdc.all.all.all", "ethereum.mainnet.usdc.all.all.all"},
+		{"Ethereum.Mainnet.USDC.*.*.*", "ethereum.mainnet.usdc.all.all.all"},
+		{"base.mainnet..." + bob + ".WHALE", "base.mainnet.all.all." + bob + ".whale"},
+		{"ethereum.sepolia." + weth + ".ALL." + strings.ToLower(weth) + ".small", "ethereum.sepolia." + weth + ".all." + strings.ToLower(weth) + ".small"},
+		{"solana.mainnet.usdc.9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM.all.large", "solana.mainnet.usdc.9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM.all.large"},
+		{"tron.mainnet.usdt.TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t.all.medium", "tron.mainnet.usdt.TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t.all.medium"},
+	}
+	for _, tt := range tests {
+		p, err := ParseTransfer(tt.in)
+		if err != nil {
+			t.Errorf("ParseTransfer(%q): %v", tt.in, err)
+			continue
+		}
+		if p.String() != tt.want {
+			t.Errorf("ParseTransfer(%q) = %s, want %s", tt.in, p, tt.want)
+		}
+	}
+}
+
+func TestParseTransferErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		segment string
+		reason  string
+	}{
+		{"ethereum.mainnet.usdc", "", "expected 6 segments"},
+		{"moon.mainnet.usdc.all.all.all", "chain", "not a known chain"},
+		{".mainnet.usdc.all.all.all", "chain", "is required"},
+		{"ethereum..usdc.all.all.all", "network", "is required"},
+		{"ethereum.main_net.usdc.all.all.all", "network", "not a valid network"},
+		{"ethereum.mainnet.us-dc.all.all.all", "token", "neither a token symbol"},
+		{"ethereum.mainnet.usdc.0x1234.all.all", "from", "not a valid ethereum address"},
+		{"ethereum.mainnet.usdc.all." + strings.Replace(weth, "C02a", "c02A", 1) + ".all", "to", "invalid checksum"},
+		{"ethereum.mainnet.usdc.all.all.huge", "size", "not a size bucket"},
+		{"solana.mainnet.usdc." + bob + ".all.all", "from", "not a valid solana address"},
+	}
+	for _, tt := range tests {
+		_, err := ParseTransfer(tt.in)
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseTransfer(%q) err = %v, want *Error", tt.in, err)
+			continue
+		}
+		if perr.Kind != "transfer" || perr.Segment != tt.segment || !strings.Contains(perr.Reason, tt.reason) {
+			t.Errorf("ParseTransfer(%q) err = %+v, want %s %q", tt.in, perr, tt.segment, tt.reason)
+		}
+	}
+}
+
+func TestParseSwapAndEvent(t *testing.T) {
+	sw, err := ParseSwap("ethereum.mainnet.WETH.*.large")
+	if err != nil || sw.String() != "ethereum.mainnet.weth.all.large" {
+		t.Errorf("ParseSwap = %s, %v", sw, err)
+	}
+	if _, err := ParseSwap("ethereum.mainnet.weth.usdc"); err == nil {
+		t.Error("ParseSwap with 4 segments succeeded")
+	}
+
+	ev, err := ParseEvent("base.mainnet." + weth + ".Transfer")
+	if err != nil || ev.Event != "Transfer" || ev.Address != weth {
+		t.Errorf("ParseEvent = %+v, %v", ev, err)
+	}
+	if ev, err := ParseEvent("base.mainnet." + weth + ".*"); err != nil || ev.Event != Wildcard {
+		t.Errorf("ParseEvent with wildcard event = %+v, %v", ev, err)
+	}
+	for _, in := range []string{"base.mainnet.all.Transfer", "base.mainnet." + weth + ".1Transfer"} {
+		if _, err := ParseEvent(in); err == nil {
+			t.Errorf("ParseEvent(%q) succeeded", in)
+		}
+	}
+}
+
+func TestValidateNormalises(t *testing.T) {
+	tr := Transfer{Chain: " Polygon ", Network: "Amoy", Token: "", From: "*", To: bob, Size: "Whale"}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "polygon.amoy.all.all." + bob + ".whale"; tr.String() != want {
+		t.Errorf("Validate normalised to %s, want %s", tr, want)
+	}
+}
+
+func newTransferFlags(t *testing.T, args ...string) *Flags {
+	t.Helper()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f := TransferFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFlagsPatterns(t *testing.T) {
+	tests := []struct {
+		flags []string
+		args  []string
+		want  []string
+	}{
+		{[]string{"--chain", "base", "--token", "USDC"}, nil, []string{"base.mainnet.usdc.all.all.all"}},
+		{nil, []string{"ethereum.mainnet.usdc.all.all.all", "ETHEREUM.mainnet.USDC.*.*.*"}, []string{"ethereum.mainnet.usdc.all.all.all"}},
+		{[]string{"--size", "whale"}, []string{"ethereum.mainnet.usdc.all.all.all", "base.mainnet.usdt.all.all.small"},
+			[]string{"ethereum.mainnet.usdc.all.all.whale", "base.mainnet.usdt.all.all.whale"}},
+	}
+	for _, tt := range tests {
+		got, err := newTransferFlags(t, tt.flags...).Patterns(tt.args)
+		if err != nil {
+			t.Errorf("Patterns(%v) with %v: %v", tt.args, tt.flags, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Patterns(%v) with %v = %v, want %v", tt.args, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestFlagsPatternsErrors(t *testing.T) {
+	if _, err := newTransferFlags(t).Patterns(nil); err == nil || !strings.Contains(err.Error(), "--chain is required") {
+		t.Errorf("Patterns without chain err = %v", err)
+	}
+	if _, err := newTransferFlags(t, "--from", "0x12").Patterns([]string{"ethereum.mainnet.usdc.all.all.all"}); err == nil {
+		t.Error("invalid --from override accepted")
+	}
+	if _, err := newTransferFlags(t).Pattern([]string{"ethereum.mainnet.usdc.all.all.all", "base.mainnet.usdc.all.all.all"}); err == nil {
+		t.Error("Pattern accepted two patterns")
+	}
+}
+
+// TestFlagsProfileChain covers a chain applied as a default by the active
+// profile, which sets the flag's value without marking it changed
+func TestFlagsProfileChain(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f := TransferFlags(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Lookup("chain").Value.Set("base"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := f.Pattern(nil)
+	if err != nil || got != "base.mainnet.all.all.all.all" {
+		t.Errorf("Pattern without args = %q, %v, want the profile chain", got, err)
+	}
+	got, err = f.Pattern([]string{"ethereum.mainnet.usdc.all.all.all"})
+	if err != nil || got != "ethereum.mainnet.usdc.all.all.all" {
+		t.Errorf("Pattern = %q, %v, want the profile chain not to override the argument", got, err)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# stablecoins\nethereum.mainnet.usdc.all.all.all\n\n  base.mainnet.usdc.all.all.all  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FromArgs([]string{"polygon.mainnet.usdc.all.all.all"}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"polygon.mainnet.usdc.all.all.all", "ethereum.mainnet.usdc.all.all.all", "base.mainnet.usdc.all.all.all"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromArgs = %v, want %v", got, want)
+	}
+	if _, err := ReadFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("ReadFile of a missing file succeeded")
+	}
+}
@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/heimdahl-xyz/heimdahl-cli/format"
+)
+
+// Record returns the transfer as an output record
+func (t Transfer) Record() format.Record {
+	return format.Record{
+		{Name: "time", Value: time.Unix(t.Timestamp, 0)},
+		{Name: "chain", Value: t.Chain},
+		{Name: "network", Value: t.Network},
+		{Name: "symbol", Value: t.Symbol},
+		{Name: "amount", Value: format.Amount{Value: t.Amount, Decimals: t.Decimals}},
+		{Name: "from", Value: t.FromAddress},
+		{Name: "to", Value: t.ToAddress},
+		{Name: "tx_hash", Value: t.TxHash},
+		{Name: "token", Value: t.TokenAddress},
+		{Name: "position", Value: t.Position},
+		{Name: "timestamp", Value: t.Timestamp},
+		{Name: "amount_raw", Value: t.Amount},
+	}
+}
+
+// Record returns the streamed transfer as an output record
+func (t FungibleTokenTransfer) Record() format.Record {
+	return format.Record{
+		{Name: "time", Value: time.Unix(t.Timestamp, 0)},
+		{Name: "chain", Value: t.Chain},
+		{Name: "network", Value: t.Network},
+		{Name: "symbol", Value: t.Symbol},
+		{Name: "amount", Value: format.Amount{Value: t.Amount, Decimals: int(t.Decimals)}},
+		{Name: "from", Value: t.FromAddress},
+		{Name: "to", Value: t.ToAddress},
+		{Name: "from_owner", Value: t.FromOwner},
+		{Name: "to_owner", Value: t.ToOwner},
+		{Name: "tx_hash", Value: t.TxHash},
+		{Name: "token", Value: t.TokenAddress},
+		{Name: "position", Value: t.Position},
+		{Name: "timestamp", Value: t.Timestamp},
+		{Name: "amount_raw", Value: t.Amount},
+	}
+}
+
+// Record returns the swap as an output record
+func (s Swap) Record() format.Record {
+	return format.Record{
+		{Name: "time", Value: time.Unix(s.Timestamp, 0)},
+		{Name: "chain", Value: s.ChainName},
+		{Name: "token1", Value: s.Token1Symbol},
+		{Name: "token1_amount", Value: format.Amount{Value: s.Token1Amount, Decimals: s.Token1Decimals}},
+		{Name: "token2", Value: s.Token2Symbol},
+		{Name: "token2_amount", Value: format.Amount{Value: s.Token2Amount, Decimals: s.Token2Decimals}},
+		{Name: "tx_hash", Value: s.TxHash},
+		{Name: "token1_address", Value: s.Token1Address},
+		{Name: "token2_address", Value: s.Token2Address},
+		{Name: "token1_sender", Value: s.Token1Sender},
+		{Name: "token2_sender", Value: s.Token2Sender},
+		{Name: "price_token1_in_token2", Value: s.PriceToken1InToken2},
+		{Name: "price_token2_in_token1", Value: s.PriceToken2InToken1},
+		{Name: "timestamp", Value: s.Timestamp},
+	}
+}
+
+// Record returns the chain as an output record
+func (c ChainInfo) Record() format.Record {
+	return format.Record{
+		{Name: "chain", Value: c.Chain},
+		{Name: "network", Value: c.Network},
+		{Name: "chain_id", Value: c.ChainID},
+	}
+}
+
+// EventNames returns the indexed event names of the contract
+func (c ContractInfo) EventNames() []string {
+	var names []string
+	for _, e := range strings.Split(c.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			names = append(names, e)
+		}
+	}
+	return names
+}
+
+// RawABI returns the contract's ABI as a JSON array, nil when the API
+// returned none
+func (c ContractInfo) RawABI() (json.RawMessage, error) {
+	raw := bytes.TrimSpace(c.ABI)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] != '"' {
+		return raw, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid ABI of %s: %w", c.ContractAddress, err)
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		return nil, nil
+	}
+	return json.RawMessage(s), nil
+}
+
+// Record returns the contract as an output record
+func (c ContractInfo) Record() format.Record {
+	return format.Record{
+		{Name: "chain", Value: c.Chain},
+		{Name: "network", Value: c.Network},
+		{Name: "name", Value: c.ContractName},
+		{Name: "address", Value: c.ContractAddress},
+		{Name: "events", Value: c.EventNames()},
+	}
+}
+
+// Record returns the event as an output record. Envelope fields come first,
+// leaving out those the event was decoded without, and the event arguments
+// are grouped under args.
+func (e Event) Record() format.Record {
+	envelope := []struct {
+		keys  []string
+		field format.Field
+	}{
+		{[]string{"blockNumber"}, format.Field{Name: "block", Value: e.BlockNumber}},
+		{[]string{"blockTimestamp", "timestamp"}, format.Field{Name: "timestamp", Value: e.Timestamp}},
+		{[]string{"transactionHash"}, format.Field{Name: "tx_hash", Value: e.TxHash}},
+		{[]string{"chain"}, format.Field{Name: "chain", Value: e.Chain}},
+		{[]string{"network"}, format.Field{Name: "network", Value: e.Network}},
+		{[]string{"blockHash"}, format.Field{Name: "block_hash", Value: e.BlockHash}},
+		{[]string{"contractAddress"}, format.Field{Name: "contract", Value: e.Contract}},
+		{[]string{"transactionIndex"}, format.Field{Name: "tx_index", Value: e.TxIndex}},
+		{[]string{"logIndex"}, format.Field{Name: "log_index", Value: e.LogIndex}},
+		{[]string{"event"}, format.Field{Name: "event", Value: e.Name}},
+	}
+
+	var r format.Record
+	for _, f := range envelope {
+		if e.has(f.keys...) {
+			r = append(r, f.field)
+		}
+	}
+	return append(r, format.Field{Name: "args", Value: e.Args})
+}
+
+// Time returns the time of the transfer, zero when unknown
+func (t Transfer) Time() time.Time {
+	return unixTime(t.Timestamp)
+}
+
+// Time returns the time of the swap, zero when unknown
+func (s Swap) Time() time.Time {
+	return unixTime(s.Timestamp)
+}
+
+func unixTime(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
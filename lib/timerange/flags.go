@@ -0,0 +1,48 @@
+package timerange
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Flags reads a range from command line flags
+type Flags struct {
+	since, until       string
+	fromBlock, toBlock uint64
+}
+
+// TimeFlags registers --since and --until on fs
+func TimeFlags(fs *pflag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.since, "since", "", "Only records at or after this time (RFC3339, date, unix seconds or relative like 24h, 7d)")
+	fs.StringVar(&f.until, "until", "", "Only records at or before this time (same formats as --since)")
+	return f
+}
+
+// BlockFlags registers --since, --until, --from-block and --to-block on fs
+func BlockFlags(fs *pflag.FlagSet) *Flags {
+	f := TimeFlags(fs)
+	fs.Uint64Var(&f.fromBlock, "from-block", 0, "Only records at or after this block")
+	fs.Uint64Var(&f.toBlock, "to-block", 0, "Only records at or before this block")
+	return f
+}
+
+// Range parses the flags into a range
+func (f *Flags) Range() (Range, error) {
+	r := Range{FromBlock: f.fromBlock, ToBlock: f.toBlock}
+	now := time.Now()
+
+	var err error
+	if f.since != "" {
+		if r.Since, err = ParseTime(f.since, now); err != nil {
+			return r, err
+		}
+	}
+	if f.until != "" {
+		if r.Until, err = ParseTime(f.until, now); err != nil {
+			return r, err
+		}
+	}
+	return r, r.Validate()
+}
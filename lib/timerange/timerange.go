@@ -0,0 +1,118 @@
+// Package timerange selects records by time and block number.
+package timerange
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range bounds records by time and block number. Zero values leave the
+// corresponding side unbounded; bounds are inclusive.
+type Range struct {
+	Since     time.Time
+	Until     time.Time
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+// Location of a record relative to a range
+type Location int
+
+const (
+	// Within the range
+	Within Location = iota
+	// Newer than the end of the range
+	Newer
+	// Older than the start of the range
+	Older
+)
+
+// IsZero reports whether the range is unbounded
+func (r Range) IsZero() bool {
+	return r.Since.IsZero() && r.Until.IsZero() && r.FromBlock == 0 && r.ToBlock == 0
+}
+
+// Validate checks that the range is not empty
+func (r Range) Validate() error {
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return fmt.Errorf("--until %s is before --since %s", r.Until.Format(time.RFC3339), r.Since.Format(time.RFC3339))
+	}
+	if r.FromBlock > 0 && r.ToBlock > 0 && r.ToBlock < r.FromBlock {
+		return fmt.Errorf("--to-block %d is before --from-block %d", r.ToBlock, r.FromBlock)
+	}
+	return nil
+}
+
+// Query returns the range as API query parameters
+func (r Range) Query() url.Values {
+	q := url.Values{}
+	if !r.Since.IsZero() {
+		q.Set("since", strconv.FormatInt(r.Since.Unix(), 10))
+	}
+	if !r.Until.IsZero() {
+		q.Set("until", strconv.FormatInt(r.Until.Unix(), 10))
+	}
+	if r.FromBlock > 0 {
+		q.Set("from_block", strconv.FormatUint(r.FromBlock, 10))
+	}
+	if r.ToBlock > 0 {
+		q.Set("to_block", strconv.FormatUint(r.ToBlock, 10))
+	}
+	return q
+}
+
+// Locate places a record with timestamp t at block within the range. A zero
+// t or block is not checked against the matching bounds.
+func (r Range) Locate(t time.Time, block uint64) Location {
+	if !t.IsZero() {
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return Newer
+		}
+		if !r.Since.IsZero() && t.Before(r.Since) {
+			return Older
+		}
+	}
+	if block > 0 {
+		if r.ToBlock > 0 && block > r.ToBlock {
+			return Newer
+		}
+		if r.FromBlock > 0 && block < r.FromBlock {
+			return Older
+		}
+	}
+	return Within
+}
+
+var relativeRe = regexp.MustCompile(`^(\d+)([smhdw])$`)
+
+// ParseTime parses an RFC3339 time, a date (2006-01-02), unix seconds or a
+// duration relative to now such as 90m, 24h, 7d or 2w
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if m := relativeRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{
+			"s": time.Second,
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[m[2]]
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339, a date, unix seconds or a relative duration like 24h or 7d", s)
+}
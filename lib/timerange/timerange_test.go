@@ -0,0 +1,133 @@
+package timerange
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30s", now.Add(-30 * time.Second)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{" 2w ", now.Add(-14 * 24 * time.Hour)},
+		{"1709294400", time.Unix(1709294400, 0)},
+		{"2024-02-29T08:30:00Z", time.Date(2024, 2, 29, 8, 30, 0, 0, time.UTC)},
+		{"2024-02-29T08:30:00+02:00", time.Date(2024, 2, 29, 6, 30, 0, 0, time.UTC)},
+		{"2024-02-29", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	for _, in := range []string{"", "yesterday", "7y", "-1d", "2024-02-30", "2024-02-29 08:30"} {
+		if got, err := ParseTime(in, now); err == nil {
+			t.Errorf("ParseTime(%q) = %s, want error", in, got)
+		}
+	}
+}
+
+func rangeFromFlags(t *testing.T, args ...string) (Range, error) {
+	t.Helper()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f := BlockFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return f.Range()
+}
+
+func TestFlagsRange(t *testing.T) {
+	r, err := rangeFromFlags(t, "--since", "2024-02-01T00:00:00Z", "--until", "1709294400", "--from-block", "10", "--to-block", "20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Range{
+		Since:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Until:     time.Unix(1709294400, 0),
+		FromBlock: 10,
+		ToBlock:   20,
+	}
+	if !r.Since.Equal(want.Since) || !r.Until.Equal(want.Until) || r.FromBlock != want.FromBlock || r.ToBlock != want.ToBlock {
+		t.Errorf("Range = %+v, want %+v", r, want)
+	}
+
+	q := r.Query()
+	if q.Get("since") != "1706745600" || q.Get("until") != "1709294400" || q.Get("from_block") != "10" || q.Get("to_block") != "20" {
+		t.Errorf("Query = %v", q)
+	}
+
+	if r, err := rangeFromFlags(t); err != nil || !r.IsZero() || len(r.Query()) != 0 {
+		t.Errorf("Range without flags = %+v, %v, want unbounded", r, err)
+	}
+}
+
+func TestFlagsRangeErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--since", "soon"}, "invalid time"},
+		{[]string{"--until", "later"}, "invalid time"},
+		{[]string{"--since", "1d", "--until", "2d"}, "is before --since"},
+		{[]string{"--from-block", "20", "--to-block", "10"}, "is before --from-block"},
+	}
+	for _, tt := range tests {
+		_, err := rangeFromFlags(t, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Range with %v err = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestTimeFlagsHaveNoBlocks(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	TimeFlags(fs)
+	if fs.Lookup("from-block") != nil || fs.Lookup("to-block") != nil {
+		t.Error("TimeFlags registered block flags")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	r := Range{Since: now.Add(-time.Hour), Until: now, FromBlock: 100, ToBlock: 200}
+	tests := []struct {
+		t     time.Time
+		block uint64
+		want  Location
+	}{
+		{now.Add(-30 * time.Minute), 150, Within},
+		{now, 200, Within},
+		{now.Add(-time.Hour), 100, Within},
+		{now.Add(time.Second), 150, Newer},
+		{now.Add(-2 * time.Hour), 150, Older},
+		{now.Add(-30 * time.Minute), 201, Newer},
+		{now.Add(-30 * time.Minute), 99, Older},
+		// zero values are not checked against their bounds
+		{time.Time{}, 150, Within},
+		{now.Add(-30 * time.Minute), 0, Within},
+		{time.Time{}, 0, Within},
+	}
+	for _, tt := range tests {
+		if got := r.Locate(tt.t, tt.block); got != tt.want {
+			t.Errorf("Locate(%s, %d) = %d, want %d", tt.t, tt.block, got, tt.want)
+		}
+	}
+}
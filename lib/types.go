@@ -1,7 +1,9 @@
 package lib
 
 import (
+	"encoding/json"
 	"math/big"
+	"strings"
 )
 
 type FungibleTokenTransfer struct {
@@ -19,3 +21,128 @@ type FungibleTokenTransfer struct {
 	Decimals     uint8    `json:"decimals"`
 	Position     uint64   `json:"position"`
 }
+
+// Key identifies the transfer. The API gives no log index, so transfers in
+// one transaction, such as batch payouts or router hops, are told apart by
+// token, sender, recipient and amount.
+func (t FungibleTokenTransfer) Key() string {
+	return transferKey(t.TxHash, t.TokenAddress, t.FromAddress, t.ToAddress, t.Amount)
+}
+
+// ListMeta describes a single page of list results
+type ListMeta struct {
+	Timestamp int64    `json:"timestamp"`
+	Chains    []string `json:"chains"`
+	Tokens    []string `json:"tokens"`
+	Page      int      `json:"page"`
+	PerPage   int      `json:"per_page"`
+	Total     int      `json:"total"`
+}
+
+// Transfer represents a token transfer transaction
+type Transfer struct {
+	Timestamp    int64    `json:"timestamp"`
+	FromAddress  string   `json:"from_address"`
+	ToAddress    string   `json:"to_address"`
+	Amount       *big.Int `json:"amount"`
+	TokenAddress string   `json:"token_address"`
+	Symbol       string   `json:"symbol"`
+	Chain        string   `json:"chain"`
+	Network      string   `json:"network"`
+	TxHash       string   `json:"tx_hash"`
+	Decimals     int      `json:"decimals"`
+	Position     int64    `json:"position"`
+}
+
+// Key identifies the transfer like FungibleTokenTransfer.Key
+func (t Transfer) Key() string {
+	return transferKey(t.TxHash, t.TokenAddress, t.FromAddress, t.ToAddress, t.Amount)
+}
+
+func transferKey(txHash, token, from, to string, amount *big.Int) string {
+	return strings.ToLower(strings.Join([]string{txHash, token, from, to, amount.String()}, ":"))
+}
+
+// TokenResponse represents a page of token transfers
+type TokenResponse struct {
+	Meta      ListMeta   `json:"meta"`
+	Transfers []Transfer `json:"transfers"`
+}
+
+// Swap represents a token swap transaction
+type Swap struct {
+	ChainName           string   `json:"chain_name"`
+	TxHash              string   `json:"tx_hash"`
+	Timestamp           int64    `json:"timestamp"`
+	Token1Address       string   `json:"token1_address"`
+	Token1Symbol        string   `json:"token1_symbol"`
+	Token1Decimals      int      `json:"token1_decimals"`
+	Token2Address       string   `json:"token2_address"`
+	Token2Symbol        string   `json:"token2_symbol"`
+	Token2Decimals      int      `json:"token2_decimals"`
+	Token1Sender        string   `json:"token1_sender"`
+	Token2Sender        string   `json:"token2_sender"`
+	Token1Amount        *big.Int `json:"token1_amount"`
+	Token2Amount        *big.Int `json:"token2_amount"`
+	PriceToken1InToken2 *big.Int `json:"price_token1_in_token2,string"`
+	PriceToken2InToken1 *big.Int `json:"price_token2_in_token1,string"`
+}
+
+// SwapData represents a page of token swaps
+type SwapData struct {
+	Meta  ListMeta `json:"meta"`
+	Swaps []Swap   `json:"swaps"`
+}
+
+type EventMeta struct {
+	Chain   string `json:"chain"`
+	ChainID int    `json:"chain_id"`
+	Address string `json:"addresss"` // Note: "addresss" has a typo; update field name accordingly if needed.
+	Event   string `json:"event"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int    `json:"total"`
+}
+
+// EventDetails represents a page of decoded contract events
+type EventDetails struct {
+	Meta    EventMeta                `json:"meta"`
+	Details []map[string]interface{} `json:"events"`
+}
+
+// ChainInfo describes a supported chain and network
+type ChainInfo struct {
+	Chain   string `json:"chain_name"`
+	Network string `json:"chain_network"`
+	ChainID int    `json:"chain_id"`
+}
+
+// ContractInfo describes an indexed contract
+type ContractInfo struct {
+	Chain           string `json:"chain"`
+	Network         string `json:"network"`
+	ContractName    string `json:"contract_name"`
+	ContractAddress string `json:"contract_address"`
+	Events          string `json:"events"`
+	// ABI is the registered ABI, either a JSON array or a string holding one
+	ABI json.RawMessage `json:"abi,omitempty"`
+}
+
+// ContractParams is the payload used to register a contract for indexing
+type ContractParams struct {
+	ProjectName     string  `json:"project_name"`
+	Chain           string  `json:"chain"`
+	Network         string  `json:"network"`
+	ContractAddress string  `json:"contract_address"`
+	ContractName    string  `json:"contract_name"`
+	EventNames      *string `json:"event_names"`
+	RawABI          *string `json:"raw_abi,omitempty"`
+}
+
+// ContractUpdate is the payload changing a registered contract, only the
+// fields set are changed
+type ContractUpdate struct {
+	ContractName *string `json:"contract_name,omitempty"`
+	EventNames   *string `json:"event_names,omitempty"`
+	RawABI       *string `json:"raw_abi,omitempty"`
+}